@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"sandbox/advent-of-code-2019/lib/grid"
+)
+
+const (
+	// the size of a field cell in the heatmap, in pixels
+	CELL_SIZE = 32
+	// the glyphs are drawn with every font pixel taking GLYPH_SCALE^2 pixels
+	GLYPH_SCALE = 2
+	// the width of a cell of the ASCII maps
+	ASCII_CELL = 4
+)
+
+// digitGlyphs is a 3x5 bitmap font, a row per string.
+var digitGlyphs = [10][5]string{
+	{"###", "#.#", "#.#", "#.#", "###"},
+	{".#.", "##.", ".#.", ".#.", "###"},
+	{"###", "..#", "###", "#..", "###"},
+	{"###", "..#", "###", "..#", "###"},
+	{"#.#", "#.#", "###", "..#", "..#"},
+	{"###", "#..", "###", "..#", "###"},
+	{"###", "#..", "###", "#.#", "###"},
+	{"###", "..#", "..#", "..#", "..#"},
+	{"###", "#.#", "###", "#.#", "###"},
+	{"###", "#.#", "###", "..#", "###"},
+}
+
+var (
+	colorSpace   = color.RGBA{0x10, 0x10, 0x18, 0xff}
+	colorStation = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	colorLabel   = color.RGBA{0x00, 0x00, 0x00, 0xff}
+)
+
+// heatColor goes from blue for the lowest count through green to red for
+// the highest one.
+func heatColor(count, min, max int) color.RGBA {
+	t := 1.0
+	if max > min {
+		t = float64(count-min) / float64(max-min)
+	}
+	if t < 0.5 {
+		u := t * 2
+		return color.RGBA{0, uint8(0xff * u), uint8(0xff * (1 - u)), 0xff}
+	}
+	u := (t - 0.5) * 2
+	return color.RGBA{uint8(0xff * u), uint8(0xff * (1 - u)), 0, 0xff}
+}
+
+func fillRect(img *image.RGBA, rect image.Rectangle, c color.Color) {
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			img.Set(x, y, c)
+		}
+	}
+}
+
+// drawLabel draws the number centered in the cell.
+func drawLabel(img *image.RGBA, cell image.Rectangle, n int, c color.Color) {
+	s := strconv.Itoa(n)
+	// a glyph is 3 pixels wide with 1 pixel between the glyphs
+	w := (len(s)*4 - 1) * GLYPH_SCALE
+	h := 5 * GLYPH_SCALE
+	x0 := cell.Min.X + (cell.Dx()-w)/2
+	y0 := cell.Min.Y + (cell.Dy()-h)/2
+	for ix, ch := range s {
+		glyph := digitGlyphs[ch-'0']
+		for gy, row := range glyph {
+			for gx, px := range row {
+				if px != '#' {
+					continue
+				}
+				x := x0 + (ix*4+gx)*GLYPH_SCALE
+				y := y0 + gy*GLYPH_SCALE
+				fillRect(img, image.Rect(x, y, x+GLYPH_SCALE, y+GLYPH_SCALE), c)
+			}
+		}
+	}
+}
+
+func countRange(counts map[grid.Point]int) (int, int) {
+	first := true
+	min, max := 0, 0
+	for _, cnt := range counts {
+		if first || cnt < min {
+			min = cnt
+		}
+		if first || cnt > max {
+			max = cnt
+		}
+		first = false
+	}
+	return min, max
+}
+
+// WriteHeatmap renders the field as a PNG with every asteroid colored by the
+// number of asteroids it sees. The station is white and the first asteroids
+// of order are labeled with their number.
+func WriteHeatmap(w io.Writer, f *Field, counts map[grid.Point]int, station grid.Point, order []grid.Point) error {
+	img := image.NewRGBA(image.Rect(0, 0, f.w*CELL_SIZE, f.h*CELL_SIZE))
+	fillRect(img, img.Bounds(), colorSpace)
+	cellOf := func(p grid.Point) image.Rectangle {
+		x, y := p.X*CELL_SIZE, p.Y*CELL_SIZE
+		// keep a pixel gap between the cells
+		return image.Rect(x+1, y+1, x+CELL_SIZE-1, y+CELL_SIZE-1)
+	}
+	min, max := countRange(counts)
+	for _, ast := range f.asteroids {
+		c := heatColor(counts[ast], min, max)
+		if ast == station {
+			c = colorStation
+		}
+		fillRect(img, cellOf(ast), c)
+	}
+	for ix, ast := range order {
+		drawLabel(img, cellOf(ast), ix+1, colorLabel)
+	}
+	return png.Encode(w, img)
+}
+
+// renderASCII draws the field with ASCII_CELL characters per cell, label
+// returns the text of an asteroid cell.
+func renderASCII(f *Field, label func(ast grid.Point) string) string {
+	isAsteroid := make(map[grid.Point]bool, len(f.asteroids))
+	for _, ast := range f.asteroids {
+		isAsteroid[ast] = true
+	}
+	var buf strings.Builder
+	for y := 0; y < f.h; y++ {
+		for x := 0; x < f.w; x++ {
+			p := grid.Point{X: x, Y: y}
+			text := string(SPACE)
+			if isAsteroid[p] {
+				text = label(p)
+			}
+			fmt.Fprintf(&buf, "%*s", ASCII_CELL, text)
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// RenderCounts draws the field with the number of visible asteroids in place
+// of every asteroid.
+func RenderCounts(f *Field, counts map[grid.Point]int) string {
+	return renderASCII(f, func(ast grid.Point) string {
+		return strconv.Itoa(counts[ast])
+	})
+}
+
+// RenderOrder draws the field with the station as X and the asteroids of
+// order labeled with their number, the rest stay #.
+func RenderOrder(f *Field, station grid.Point, order []grid.Point) string {
+	labels := make(map[grid.Point]int, len(order))
+	for ix, ast := range order {
+		labels[ast] = ix + 1
+	}
+	return renderASCII(f, func(ast grid.Point) string {
+		if ast == station {
+			return "X"
+		}
+		if n, ok := labels[ast]; ok {
+			return strconv.Itoa(n)
+		}
+		return string(ASTEROID)
+	})
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"sort"
+
+	"sandbox/advent-of-code-2019/lib/grid"
+)
+
+// half splits the directions into the ones the laser passes first, from up
+// inclusive to down exclusive, and the rest.
+func half(d grid.Point) int {
+	if d.X > 0 || (d.X == 0 && d.Y < 0) {
+		return 0
+	}
+	return 1
+}
+
+// clockwiseLess orders the directions clockwise starting from up. Y grows
+// downwards, so a positive cross product means b is clockwise of a. Within a
+// half plane it never takes more than half a turn, which makes the cross
+// product an exact comparison.
+func clockwiseLess(a, b grid.Point) bool {
+	if ha, hb := half(a), half(b); ha != hb {
+		return ha < hb
+	}
+	return a.X*b.Y-a.Y*b.X > 0
+}
+
+func dist2(from, to grid.Point) int {
+	d := to.Sub(from)
+	return d.X*d.X + d.Y*d.Y
+}
+
+// rays groups the other asteroids by the exact direction from the station:
+// the reduced vector stands for the rational slope. The rays go clockwise
+// from up and every ray goes from the closest asteroid on.
+func (f *Field) rays(station grid.Point) [][]grid.Point {
+	byDir := make(map[grid.Point][]grid.Point)
+	dirs := make([]grid.Point, 0, 1)
+	for _, ast := range f.asteroids {
+		if ast == station {
+			continue
+		}
+		dir := direction(station, ast)
+		if _, ok := byDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		byDir[dir] = append(byDir[dir], ast)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		return clockwiseLess(dirs[i], dirs[j])
+	})
+	res := make([][]grid.Point, 0, len(dirs))
+	for _, dir := range dirs {
+		ray := byDir[dir]
+		sort.Slice(ray, func(i, j int) bool {
+			return dist2(station, ray[i]) < dist2(station, ray[j])
+		})
+		res = append(res, ray)
+	}
+	return res
+}
+
+// VaporizationIter goes over the asteroids in the order the laser hits them:
+// every turn it vaporizes the closest remaining asteroid on every ray.
+type VaporizationIter struct {
+	rays  [][]grid.Point
+	round int
+	ray   int
+	cur   grid.Point
+}
+
+// VaporizationOrder returns an iterator over the asteroids vaporized by the
+// laser at the station.
+func (f *Field) VaporizationOrder(station grid.Point) *VaporizationIter {
+	return &VaporizationIter{
+		rays: f.rays(station),
+		ray:  -1,
+	}
+}
+
+func (it *VaporizationIter) Next() bool {
+	it.ray++
+	if it.ray == len(it.rays) {
+		// drop the rays emptied in this round
+		rays := it.rays[:0]
+		for _, ray := range it.rays {
+			if len(ray) > it.round+1 {
+				rays = append(rays, ray)
+			}
+		}
+		it.rays = rays
+		it.round++
+		it.ray = 0
+	}
+	if it.ray >= len(it.rays) {
+		return false
+	}
+	it.cur = it.rays[it.ray][it.round]
+	return true
+}
+
+func (it *VaporizationIter) Value() grid.Point {
+	return it.cur
+}
+
+// vaporizedFirst returns up to n first asteroids hit by the laser.
+func (f *Field) vaporizedFirst(station grid.Point, n int) []grid.Point {
+	var res []grid.Point
+	for it := f.VaporizationOrder(station); len(res) < n && it.Next(); {
+		res = append(res, it.Value())
+	}
+	return res
+}
+
+// Vaporized returns the n-th asteroid hit by the laser at the station,
+// starting with 1. Whole turns are skipped instead of walked. ok is false if
+// there are less than n asteroids.
+func (f *Field) Vaporized(station grid.Point, n int) (grid.Point, bool) {
+	if n < 1 {
+		return grid.Point{}, false
+	}
+	rays := f.rays(station)
+	for round := 0; len(rays) > 0; round++ {
+		if n <= len(rays) {
+			return rays[n-1][round], true
+		}
+		n -= len(rays)
+		left := rays[:0]
+		for _, ray := range rays {
+			if len(ray) > round+1 {
+				left = append(left, ray)
+			}
+		}
+		rays = left
+	}
+	return grid.Point{}, false
+}
@@ -1,21 +1,24 @@
 package main
 
 import (
-	"bufio"
+	"flag"
+	"fmt"
 	"io"
 	"log"
-	"math"
 	"os"
 	"sort"
+
+	"sandbox/advent-of-code-2019/lib/grid"
 )
 
-type Coord struct {
-	x, y int
-}
+const (
+	ASTEROID = '#'
+	SPACE    = '.'
+)
 
 type Field struct {
 	w, h      int
-	asteroids []Coord
+	asteroids []grid.Point
 }
 
 func abs(n int) int {
@@ -25,8 +28,8 @@ func abs(n int) int {
 	return n
 }
 
-func dist(c1, c2 Coord) int {
-	return abs(c1.x-c2.x) + abs(c1.y-c2.y)
+func dist(c1, c2 grid.Point) int {
+	return abs(c1.X-c2.X) + abs(c1.Y-c2.Y)
 }
 
 func gcd(a, b int) int {
@@ -43,144 +46,127 @@ func norm(a, b int) (int, int) {
 	return a / mod, b / mod
 }
 
-func calcConnections(f *Field) map[Coord]int {
-	res := make(map[Coord]int)
-	for _, ast := range f.asteroids {
-		// create a map of other asteroids
-		rem := make([]Coord, len(f.asteroids))
-		copy(rem, f.asteroids)
-		cnt := 0
-		// sort by the distance
-		sort.Slice(rem, func(i, j int) bool {
-			return dist(ast, rem[i]) < dist(ast, rem[j])
-		})
-		log.Printf("Coord: %+v, sorted rem: %+v", ast, rem)
-		scratch := make(map[Coord]struct{})
-		scratch[ast] = struct{}{}
-		for _, rr := range rem {
-			if _, ok := scratch[rr]; ok {
-				continue
-			}
-			cnt++
-			x, y := ast.x, ast.y
-			dx, dy := norm(rr.x-ast.x, rr.y-ast.y)
-			for x >= 0 && x < f.w && y >= 0 && y < f.h {
-				scratch[Coord{x, y}] = struct{}{}
-				x += dx
-				y += dy
-			}
-		}
-		res[ast] = cnt
-	}
-	return res
+// direction reduces the vector from one point to another by the gcd of its
+// components: the points on the same ray from the origin share it.
+func direction(from, to grid.Point) grid.Point {
+	dx, dy := norm(to.X-from.X, to.Y-from.Y)
+	return grid.Point{X: dx, Y: dy}
 }
 
-func ReadField(in io.Reader) *Field {
-	sc := bufio.NewScanner(in)
-	asteroids := make([]Coord, 0, 1)
-	w, h := 0, 0
-	for sc.Scan() {
-		line := sc.Text()
-		h++
-		if w <= 0 {
-			w = len(line)
+// closestByDirection buckets the other asteroids by their direction from the
+// station and keeps the closest one in every bucket: the rest are hidden
+// behind it.
+func (f *Field) closestByDirection(station grid.Point) map[grid.Point]grid.Point {
+	closest := make(map[grid.Point]grid.Point)
+	for _, ast := range f.asteroids {
+		if ast == station {
+			continue
 		}
-		for x, ch := range line {
-			switch ch {
-			case '.':
-			case '#':
-				asteroids = append(asteroids, Coord{x, h - 1})
-			default:
-				log.Fatalf("Unknown char: %c", ch)
-			}
+		dir := direction(station, ast)
+		// on the same ray the manhattan distance grows with the real one
+		if c, ok := closest[dir]; !ok || dist(station, ast) < dist(station, c) {
+			closest[dir] = ast
 		}
 	}
-	return &Field{
-		w:         w,
-		h:         h,
-		asteroids: asteroids,
-	}
+	return closest
 }
 
-type RadialCoord struct {
-	cartesian Coord
-	angle     float64
-	r         int
+// VisibleFrom returns the asteroids in direct line of sight from the station
+// row by row.
+func (f *Field) VisibleFrom(station grid.Point) []grid.Point {
+	closest := f.closestByDirection(station)
+	res := make([]grid.Point, 0, len(closest))
+	for _, ast := range closest {
+		res = append(res, ast)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Y != res[j].Y {
+			return res[i].Y < res[j].Y
+		}
+		return res[i].X < res[j].X
+	})
+	return res
 }
 
-func polarCoord(p1, p2 Coord) RadialCoord {
-	dx := p2.x - p1.x
-	dy := p2.y - p1.y
-	// The angle is somewhat weird as it goes from vertical and then
-	// clockwise
-	angle := math.Pi/2 - math.Atan2(float64(-dy), float64(dx))
-	if angle < 0 {
-		angle += 2 * math.Pi
-	}
-	// r is not a real radius: it's a manhattan distance
-	r := abs(dx) + abs(dy)
-	return RadialCoord{
-		cartesian: p2,
-		angle:     angle,
-		r:         r,
+// calcConnections returns the number of asteroids visible from every one.
+func calcConnections(f *Field) map[grid.Point]int {
+	res := make(map[grid.Point]int, len(f.asteroids))
+	for _, ast := range f.asteroids {
+		res[ast] = len(f.closestByDirection(ast))
 	}
+	return res
 }
 
-func sortedPolarCoords(base Coord, asteroids []Coord) []RadialCoord {
-	res := make([]RadialCoord, 0, len(asteroids)-1)
-	for _, ast := range asteroids {
-		if ast == base {
-			continue
+// BestStation returns the asteroid seeing the most other ones, the first one
+// row by row on a tie.
+func (f *Field) BestStation() (grid.Point, int) {
+	conns := calcConnections(f)
+	var best grid.Point
+	maxConn := -1
+	for _, ast := range f.asteroids {
+		if conns[ast] > maxConn {
+			best, maxConn = ast, conns[ast]
 		}
-		res = append(res, polarCoord(base, ast))
 	}
+	return best, maxConn
+}
 
-	sort.Slice(res, func(i, j int) bool {
-		if res[i].angle != res[j].angle {
-			return res[i].angle < res[j].angle
+func ReadField(in io.Reader) (*Field, error) {
+	g, err := grid.Read(in)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range g.Rows() {
+		for _, ch := range row {
+			if ch != ASTEROID && ch != SPACE {
+				return nil, fmt.Errorf("unknown char: %c", ch)
+			}
 		}
-		return res[i].r < res[j].r
-	})
-
-	return res
+	}
+	return &Field{
+		w:         g.Width(),
+		h:         g.Height(),
+		asteroids: g.Find(ASTEROID),
+	}, nil
 }
 
 func main() {
-	file, err := os.Open("INPUT-TST2")
+	input := flag.String("input", "INPUT-TST2", "asteroid field file")
+	nth := flag.Int("n", 200, "vaporized asteroid to report")
+	heatmap := flag.String("png", "", "write the visibility heatmap to this PNG file")
+	ascii := flag.Bool("ascii", false, "print the visibility counts and the vaporization order")
+	flag.Parse()
+
+	file, err := os.Open(*input)
 	noerr(err)
 	defer file.Close()
-	field := ReadField(file)
+	field, err := ReadField(file)
+	noerr(err)
 	log.Printf("field: %+v", field)
 
-	conns := calcConnections(field)
-	log.Printf("Connections: %+v", conns)
-	maxConn := 0
-	var maxCoord Coord
-	for coord, conn := range conns {
-		if conn > maxConn {
-			maxConn = conn
-			maxCoord = coord
-		}
-	}
+	maxCoord, maxConn := field.BestStation()
 	log.Printf("Max conns: %d at pos: %+v", maxConn, maxCoord)
-	asteroids := sortedPolarCoords(maxCoord, field.asteroids)
-
-	cnt := 0
-	//n := 200
-	var head RadialCoord
-	for len(asteroids) > 0 {
-		head, asteroids = asteroids[0], asteroids[1:]
-		cnt++
-		log.Printf("%d-th asteroid: %+v, mult: %d", cnt, head, head.cartesian.x*100+head.cartesian.y)
-		//if cnt == n {
-		//	break
-		//}
-		tail := make([]RadialCoord, 0, 1)
-		for len(asteroids) > 0 && head.angle == asteroids[0].angle {
-			tail = append(tail, asteroids[0])
-			asteroids = asteroids[1:]
-		}
-		asteroids = append(asteroids, tail...)
+
+	ast, ok := field.Vaporized(maxCoord, *nth)
+	if !ok {
+		log.Fatalf("Less than %d asteroids to vaporize", *nth)
+	}
+	log.Printf("%d-th asteroid: %+v, mult: %d", *nth, ast, ast.X*100+ast.Y)
+
+	if *heatmap == "" && !*ascii {
+		return
+	}
+	counts := calcConnections(field)
+	order := field.vaporizedFirst(maxCoord, *nth)
+	if *ascii {
+		fmt.Printf("Visible asteroids:\n%s\n", RenderCounts(field, counts))
+		fmt.Printf("Vaporization order:\n%s", RenderOrder(field, maxCoord, order))
+	}
+	if *heatmap != "" {
+		out, err := os.Create(*heatmap)
+		noerr(err)
+		noerr(WriteHeatmap(out, field, counts, maxCoord, order))
+		noerr(out.Close())
 	}
 }
 
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"os"
+	"testing"
+
+	"sandbox/advent-of-code-2019/lib/grid"
+)
+
+func loadField(t testing.TB, path string) *Field {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer file.Close()
+	field, err := ReadField(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return field
+}
+
+func TestField_BestStation(t *testing.T) {
+	tests := []struct {
+		input   string
+		station grid.Point
+		visible int
+	}{
+		{"INPUT-TST", grid.Point{X: 3, Y: 4}, 8},
+		{"INPUT-TST3", grid.Point{X: 5, Y: 8}, 33},
+		{"INPUT-TST4", grid.Point{X: 1, Y: 2}, 35},
+		{"INPUT-TST5", grid.Point{X: 6, Y: 3}, 41},
+		{"INPUT-TST2", grid.Point{X: 11, Y: 13}, 210},
+	}
+	for _, tt := range tests {
+		station, visible := loadField(t, tt.input).BestStation()
+		if station != tt.station || visible != tt.visible {
+			t.Errorf("%s: BestStation() = %+v, %d, want %+v, %d", tt.input, station, visible, tt.station, tt.visible)
+		}
+	}
+}
+
+func TestCalcConnections(t *testing.T) {
+	// the counts from the puzzle text
+	want := map[grid.Point]int{
+		{X: 1, Y: 0}: 7, {X: 4, Y: 0}: 7,
+		{X: 0, Y: 2}: 6, {X: 1, Y: 2}: 7, {X: 2, Y: 2}: 7, {X: 3, Y: 2}: 7, {X: 4, Y: 2}: 5,
+		{X: 4, Y: 3}: 7,
+		{X: 3, Y: 4}: 8, {X: 4, Y: 4}: 7,
+	}
+	got := calcConnections(loadField(t, "INPUT-TST"))
+	if len(got) != len(want) {
+		t.Errorf("got %d asteroids, want %d", len(got), len(want))
+	}
+	for ast, cnt := range want {
+		if got[ast] != cnt {
+			t.Errorf("%+v sees %d asteroids, want %d", ast, got[ast], cnt)
+		}
+	}
+}
+
+// blocked walks the grid points between 2 asteroids.
+func blocked(asteroids map[grid.Point]bool, from, to grid.Point) bool {
+	dir := direction(from, to)
+	for p := from.Add(dir); p != to; p = p.Add(dir) {
+		if asteroids[p] {
+			return true
+		}
+	}
+	return false
+}
+
+// The visible set must be exactly the asteroids with nothing in between.
+func TestField_VisibleFrom(t *testing.T) {
+	for _, input := range []string{"INPUT-TST", "INPUT-TST3", "INPUT-TST4", "INPUT-TST5", "INPUT-TST2"} {
+		field := loadField(t, input)
+		asteroids := make(map[grid.Point]bool, len(field.asteroids))
+		for _, ast := range field.asteroids {
+			asteroids[ast] = true
+		}
+		for _, station := range field.asteroids {
+			visible := make(map[grid.Point]bool)
+			for _, ast := range field.VisibleFrom(station) {
+				visible[ast] = true
+			}
+			for _, ast := range field.asteroids {
+				if ast == station {
+					continue
+				}
+				if want := !blocked(asteroids, station, ast); visible[ast] != want {
+					t.Fatalf("%s: %+v seen from %+v: %t, want %t", input, ast, station, visible[ast], want)
+				}
+			}
+		}
+	}
+}
+
+func TestField_VaporizationOrder(t *testing.T) {
+	field := loadField(t, "INPUT-TST6")
+	it := field.VaporizationOrder(grid.Point{X: 8, Y: 3})
+	want := []grid.Point{
+		{X: 8, Y: 1}, {X: 9, Y: 0}, {X: 9, Y: 1}, {X: 10, Y: 0}, {X: 9, Y: 2},
+		{X: 11, Y: 1}, {X: 12, Y: 1}, {X: 11, Y: 2}, {X: 15, Y: 1},
+	}
+	for ix, w := range want {
+		if !it.Next() {
+			t.Fatalf("the iterator stopped after %d asteroids", ix)
+		}
+		if got := it.Value(); got != w {
+			t.Errorf("asteroid %d = %+v, want %+v", ix+1, got, w)
+		}
+	}
+}
+
+func TestField_Vaporized(t *testing.T) {
+	field := loadField(t, "INPUT-TST2")
+	station := grid.Point{X: 11, Y: 13}
+	want := map[int]grid.Point{
+		1: {X: 11, Y: 12}, 2: {X: 12, Y: 1}, 3: {X: 12, Y: 2}, 10: {X: 12, Y: 8},
+		20: {X: 16, Y: 0}, 50: {X: 16, Y: 9}, 100: {X: 10, Y: 16}, 199: {X: 9, Y: 6},
+		200: {X: 8, Y: 2}, 201: {X: 10, Y: 9}, 299: {X: 11, Y: 1},
+	}
+	for n, w := range want {
+		if got, ok := field.Vaporized(station, n); !ok || got != w {
+			t.Errorf("Vaporized(%d) = %+v, %t, want %+v", n, got, ok, w)
+		}
+	}
+	if _, ok := field.Vaporized(station, 300); ok {
+		t.Errorf("there are only 299 asteroids to vaporize")
+	}
+}
+
+// The iterator and the direct lookup must agree and hit every asteroid once.
+func TestField_Vaporized_Iterator(t *testing.T) {
+	for _, input := range []string{"INPUT-TST", "INPUT-TST3", "INPUT-TST4", "INPUT-TST5", "INPUT-TST6", "INPUT-TST2"} {
+		field := loadField(t, input)
+		stations := field.asteroids
+		if len(stations) > 100 {
+			// every station of a big field takes too long
+			best, _ := field.BestStation()
+			stations = []grid.Point{best, stations[0], stations[len(stations)-1]}
+		}
+		for _, station := range stations {
+			seen := make(map[grid.Point]bool)
+			n := 0
+			for it := field.VaporizationOrder(station); it.Next(); {
+				n++
+				ast := it.Value()
+				if seen[ast] || ast == station {
+					t.Fatalf("%s from %+v: %+v hit twice", input, station, ast)
+				}
+				seen[ast] = true
+				if got, ok := field.Vaporized(station, n); !ok || got != ast {
+					t.Fatalf("%s from %+v: Vaporized(%d) = %+v, want %+v", input, station, n, got, ast)
+				}
+			}
+			if n != len(field.asteroids)-1 {
+				t.Fatalf("%s from %+v: %d asteroids hit, want %d", input, station, n, len(field.asteroids)-1)
+			}
+		}
+	}
+}
+
+func TestClockwiseLess(t *testing.T) {
+	// clockwise from up, including the slopes a float angle could confuse
+	dirs := []grid.Point{
+		{X: 0, Y: -1}, {X: 1, Y: -1000}, {X: 1, Y: -999}, {X: 1, Y: -1}, {X: 1, Y: 0},
+		{X: 1, Y: 1}, {X: 0, Y: 1}, {X: -1, Y: 1}, {X: -1, Y: 0}, {X: -1, Y: -1}, {X: -1, Y: -1000},
+	}
+	for i := range dirs {
+		for j := range dirs {
+			if got := clockwiseLess(dirs[i], dirs[j]); got != (i < j) {
+				t.Errorf("clockwiseLess(%+v, %+v) = %t, want %t", dirs[i], dirs[j], got, i < j)
+			}
+		}
+	}
+}
+
+func TestRenderCounts(t *testing.T) {
+	field := loadField(t, "INPUT-TST")
+	want := "" +
+		"   .   7   .   .   7\n" +
+		"   .   .   .   .   .\n" +
+		"   6   7   7   7   5\n" +
+		"   .   .   .   .   7\n" +
+		"   .   .   .   8   7\n"
+	if got := RenderCounts(field, calcConnections(field)); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderOrder(t *testing.T) {
+	field := loadField(t, "INPUT-TST")
+	station := grid.Point{X: 3, Y: 4}
+	want := "" +
+		"   .   #   .   .   2\n" +
+		"   .   .   .   .   .\n" +
+		"   #   #   #   1   3\n" +
+		"   .   .   .   .   4\n" +
+		"   .   .   .   X   #\n"
+	if got := RenderOrder(field, station, field.vaporizedFirst(station, 4)); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteHeatmap(t *testing.T) {
+	field := loadField(t, "INPUT-TST")
+	station := grid.Point{X: 3, Y: 4}
+	counts := calcConnections(field)
+	var buf bytes.Buffer
+	if err := WriteHeatmap(&buf, field, counts, station, field.vaporizedFirst(station, 1)); err != nil {
+		t.Fatalf("WriteHeatmap: %s", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("decoding the heatmap: %s", err)
+	}
+	if b := img.Bounds(); b.Dx() != 5*CELL_SIZE || b.Dy() != 5*CELL_SIZE {
+		t.Fatalf("got %dx%d image, want %dx%d", b.Dx(), b.Dy(), 5*CELL_SIZE, 5*CELL_SIZE)
+	}
+	// the corners of the cells stay clear of the labels
+	at := func(p grid.Point) color.RGBA {
+		return color.RGBAModel.Convert(img.At(p.X*CELL_SIZE+2, p.Y*CELL_SIZE+2)).(color.RGBA)
+	}
+	cases := []struct {
+		p    grid.Point
+		want color.RGBA
+	}{
+		{grid.Point{X: 0, Y: 0}, colorSpace},
+		{station, colorStation},
+		{grid.Point{X: 4, Y: 2}, heatColor(5, 5, 8)},
+		{grid.Point{X: 0, Y: 2}, heatColor(6, 5, 8)},
+		{grid.Point{X: 4, Y: 0}, heatColor(7, 5, 8)},
+	}
+	for _, c := range cases {
+		if got := at(c.p); got != c.want {
+			t.Errorf("cell %+v: got %v, want %v", c.p, got, c.want)
+		}
+	}
+	// the first vaporized asteroid has a label in the middle of its cell
+	labeled := false
+	for y := 0; y < CELL_SIZE; y++ {
+		for x := 0; x < CELL_SIZE; x++ {
+			if color.RGBAModel.Convert(img.At(3*CELL_SIZE+x, 2*CELL_SIZE+y)).(color.RGBA) == colorLabel {
+				labeled = true
+			}
+		}
+	}
+	if !labeled {
+		t.Errorf("no label on the first vaporized asteroid")
+	}
+}
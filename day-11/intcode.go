@@ -170,11 +170,11 @@ func Compute(program *Program, input <-chan int64, output chan<- int64) {
 			_, mode1, mode2 := program.OpDuo(pcnt)
 			log.Printf("duo mode: %d, %d", mode1, mode2)
 			cond, dest := program.ReadVal(rel, pcnt+1, mode1), program.ReadVal(rel, pcnt+2, mode2)
-			if cond > 0 {
-				log.Printf("cond %d > 0, jump to %d", cond, dest)
+			if cond != 0 {
+				log.Printf("cond %d != 0, jump to %d", cond, dest)
 				pcnt = dest
 			} else {
-				log.Printf("cond %d is not > 0, continue", cond)
+				log.Printf("cond %d is 0, continue", cond)
 				pcnt += 3
 			}
 		case CODE_JMPZ:
@@ -1,75 +1,110 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"math/big"
+	"os"
 	"strings"
+	"sync"
 )
 
-type Vector3 struct {
-	x, y, z int
-}
-
-func (v Vector3) Add(v2 Vector3) Vector3 {
-	return Vector3{
-		x: v.x + v2.x,
-		y: v.y + v2.y,
-		z: v.z + v2.z,
-	}
-}
+var (
+	ErrDimensions = errors.New("bodies have different number of dimensions")
+)
 
 type Planet struct {
-	pos Vector3
-	vel Vector3
+	pos Vector
+	vel Vector
 }
 
-func NewPlanet(pos Vector3) *Planet {
+func NewPlanet(pos Vector) *Planet {
 	return &Planet{
 		pos: pos,
-		vel: Vector3{0, 0, 0},
+		vel: NewVector(pos.Dims()),
 	}
 }
 
 func (p *Planet) String() string {
-	return fmt.Sprintf("pos=<x=%d, y=%d, z=%d>, vel=<x=%d, y=%d, z=%d>",
-		p.pos.x, p.pos.y, p.pos.z, p.vel.x, p.vel.y, p.vel.z)
+	return fmt.Sprintf("pos=%s, vel=%s", p.pos, p.vel)
 }
 
 func (p *Planet) Pot() int {
-	return abs(p.pos.x) + abs(p.pos.y) + abs(p.pos.z)
+	return p.pos.Norm1()
 }
 
 func (p *Planet) Kin() int {
-	return abs(p.vel.x) + abs(p.vel.y) + abs(p.vel.z)
+	return p.vel.Norm1()
 }
 
 func (p *Planet) EqualsTo(p2 *Planet) bool {
-	return p.pos == p2.pos && p.vel == p2.vel
+	return p.pos.Equals(p2.pos) && p.vel.Equals(p2.vel)
 }
 
+// Interaction is the velocity change a body gets along an axis from another
+// body: pos and other are their coordinates on that axis. The rule is applied
+// to every axis separately, so the axes never affect each other.
+type Interaction func(pos, other int) int
+
 type PlanetSystem struct {
 	planets []*Planet
+	dims    int
+	rule    Interaction
 	time    int
 }
 
-func NewPlanetSystem(planets []*Planet) *PlanetSystem {
-	return &PlanetSystem{
+// Option tweaks the way the system is simulated.
+type Option func(ps *PlanetSystem)
+
+// WithInteraction replaces the puzzle pull of posToGrav with a custom rule.
+func WithInteraction(rule Interaction) Option {
+	return func(ps *PlanetSystem) {
+		ps.rule = rule
+	}
+}
+
+// NewPlanetSystem creates a system of bodies with the same number of
+// dimensions. The bodies pull each other by 1 along every axis unless a
+// custom interaction is given.
+func NewPlanetSystem(planets []*Planet, opts ...Option) (*PlanetSystem, error) {
+	ps := &PlanetSystem{
 		planets: planets,
+		rule:    posToGrav,
+	}
+	for ix, planet := range planets {
+		if ix == 0 {
+			ps.dims = planet.pos.Dims()
+		}
+		if planet.pos.Dims() != ps.dims || planet.vel.Dims() != ps.dims {
+			return nil, fmt.Errorf("%w: body %d is %d-dimensional, want %d", ErrDimensions, ix, planet.pos.Dims(), ps.dims)
+		}
+	}
+	for _, opt := range opts {
+		opt(ps)
 	}
+	return ps, nil
+}
+
+func (ps *PlanetSystem) Dims() int {
+	return ps.dims
+}
+
+func (ps *PlanetSystem) Time() int {
+	return ps.time
 }
 
 func (ps *PlanetSystem) adjustGravity() {
 	for _, planet := range ps.planets {
-		var dv Vector3
+		dv := NewVector(ps.dims)
 		for _, another := range ps.planets {
 			if planet == another {
 				continue
 			}
-			dv = dv.Add(Vector3{
-				posToGrav(planet.pos.x, another.pos.x),
-				posToGrav(planet.pos.y, another.pos.y),
-				posToGrav(planet.pos.z, another.pos.z),
-			})
+			for ix := range dv {
+				dv[ix] += ps.rule(planet.pos[ix], another.pos[ix])
+			}
 		}
 		planet.vel = planet.vel.Add(dv)
 	}
@@ -98,33 +133,107 @@ func (ps *PlanetSystem) Energy() int {
 func (ps *PlanetSystem) Snapshot() *PlanetSystem {
 	return &PlanetSystem{
 		planets: cpPlanets(ps.planets),
+		dims:    ps.dims,
+		rule:    ps.rule,
+		time:    ps.time,
 	}
 }
 
 func (ps *PlanetSystem) EqualsTo(s *PlanetSystem) bool {
-	total := len(s.planets)
-	xMatch, yMatch, zMatch := 0, 0, 0
-	for ix := range ps.planets {
-		if ps.planets[ix].pos.x == s.planets[ix].pos.x && ps.planets[ix].vel.x == s.planets[ix].vel.x {
-			xMatch++
+	if len(ps.planets) != len(s.planets) || ps.dims != s.dims {
+		return false
+	}
+	for ix := 0; ix < ps.dims; ix++ {
+		if !ps.axis(ix).equalsTo(s.axis(ix)) {
+			return false
 		}
-		if ps.planets[ix].pos.y == s.planets[ix].pos.y && ps.planets[ix].vel.y == s.planets[ix].vel.y {
-			yMatch++
+	}
+	return true
+}
+
+// axisState is the projection of the system on a single axis. The axes do
+// not affect each other, so every one of them could be simulated on its own.
+type axisState struct {
+	pos, vel []int
+	rule     Interaction
+}
+
+func (ps *PlanetSystem) axis(ix int) axisState {
+	st := axisState{
+		pos:  make([]int, len(ps.planets)),
+		vel:  make([]int, len(ps.planets)),
+		rule: ps.rule,
+	}
+	for i, planet := range ps.planets {
+		st.pos[i] = planet.pos[ix]
+		st.vel[i] = planet.vel[ix]
+	}
+	return st
+}
+
+func (st axisState) tick() {
+	for i := range st.pos {
+		for j := range st.pos {
+			if i != j {
+				st.vel[i] += st.rule(st.pos[i], st.pos[j])
+			}
 		}
-		if ps.planets[ix].pos.z == s.planets[ix].pos.z && ps.planets[ix].vel.z == s.planets[ix].vel.z {
-			zMatch++
+	}
+	for i := range st.pos {
+		st.pos[i] += st.vel[i]
+	}
+}
+
+func (st axisState) equalsTo(st2 axisState) bool {
+	for i := range st.pos {
+		if st.pos[i] != st2.pos[i] || st.vel[i] != st2.vel[i] {
+			return false
 		}
 	}
-	if xMatch == total {
-		log.Printf("X match on %d", ps.time)
+	return true
+}
+
+// period simulates the axis until it returns to the initial state. Whatever
+// the interaction rule is, a step could be undone: the previous positions are
+// the current ones minus the velocities and the pull only depends on the
+// positions. So the first repeated state is always the initial one. A rule
+// pushing the bodies apart forever never repeats a state though and the
+// simulation never ends.
+func (st axisState) period() int {
+	cur := axisState{
+		pos:  append([]int{}, st.pos...),
+		vel:  append([]int{}, st.vel...),
+		rule: st.rule,
 	}
-	if yMatch == total {
-		log.Printf("Y match on %d", ps.time)
+	for steps := 1; ; steps++ {
+		cur.tick()
+		if cur.equalsTo(st) {
+			return steps
+		}
 	}
-	if zMatch == total {
-		log.Printf("Z match on %d", ps.time)
+}
+
+// Period returns the number of ticks it takes the system to return to its
+// current state. Every axis is simulated in its own goroutine and the
+// system period is the LCM of the axis periods, which might not fit into an
+// int.
+func (ps *PlanetSystem) Period() *big.Int {
+	periods := make([]int, ps.dims)
+	var wg sync.WaitGroup
+	for ix := range periods {
+		wg.Add(1)
+		go func(ix int) {
+			defer wg.Done()
+			periods[ix] = ps.axis(ix).period()
+		}(ix)
 	}
-	return total == xMatch && total == yMatch && total == zMatch
+	wg.Wait()
+
+	res := big.NewInt(1)
+	for _, p := range periods {
+		res = lcm(res, big.NewInt(int64(p)))
+	}
+	return res
 }
 
 func (ps *PlanetSystem) String() string {
@@ -135,6 +244,12 @@ func (ps *PlanetSystem) String() string {
 	return strings.Join(chunks, "\n")
 }
 
+func noerr(err error) {
+	if err != nil {
+		log.Fatalf("Unexpected error: %s", err)
+	}
+}
+
 func abs(v int) int {
 	if v < 0 {
 		return -v
@@ -151,116 +266,59 @@ func posToGrav(v1, v2 int) int {
 	return 0
 }
 
+func newPlanets(scan []Vector) []*Planet {
+	planets := make([]*Planet, 0, len(scan))
+	for _, s := range scan {
+		planets = append(planets, NewPlanet(s))
+	}
+	return planets
+}
+
 func cpPlanets(planets []*Planet) []*Planet {
 	res := make([]*Planet, 0, len(planets))
 	for _, planet := range planets {
 		res = append(res, &Planet{
-			pos: planet.pos,
-			vel: planet.vel,
+			pos: planet.pos.Clone(),
+			vel: planet.vel.Clone(),
 		})
 	}
 	return res
 }
 
 func main() {
-	scan := []Vector3{
-		{-13, -13, -13},
-		{5, -8, 3},
-		{-6, -10, -3},
-		{0, 5, -5},
-	}
-	//scan := []Vector3{
-	//	{-1, 0, 2},
-	//	{2, -10, -7},
-	//	{4, -8, 8},
-	//	{3, 5, -1},
-	//}
-	//scan := []Vector3{
-	//	{-8, -10, 0},
-	//	{5, 5, 10},
-	//	{2, -7, 3},
-	//	{9, -8, -3},
-	//}
-	/*
-		<x=-13, y=-13, z=-13>
-		<x=5, y=-8, z=3>
-		<x=-6, y=-10, z=-3>
-		<x=0, y=5, z=-5>
-	*/
-
-	planets := make([]*Planet, 0, len(scan))
-	for _, s := range scan {
-		planets = append(planets, NewPlanet(s))
+	input := flag.String("input", "INPUT", "moon scan file, - for stdin")
+	steps := flag.Int("steps", 1000, "ticks to simulate before measuring the energy")
+	trajectory := flag.String("csv", "", "write the trajectory over the steps to this CSV file")
+	flag.Parse()
+
+	var scan []Vector
+	var err error
+	if *input == "-" {
+		scan, err = ReadScan(os.Stdin)
+	} else {
+		scan, err = loadScan(*input)
 	}
-
-	ps := NewPlanetSystem(planets)
-	snap := ps.Snapshot()
-
-	//log.Printf("initial state:\n%s", ps)
-
-	//T := 1000
-	//for i := 0; i < T; i++ {
-	//	ps.Tick()
-	//	//log.Printf("tick %d:\n%s", i, ps)
-	//}
-
-	//energy := ps.Energy()
-
-	//log.Printf("Total energy: %d", energy)
-
-	total := len(ps.planets)
-	var periods [3]int
-	set := 0
-	for {
-		ps.Tick()
-		xMatch, yMatch, zMatch := 0, 0, 0
-		for ix := range ps.planets {
-			if ps.planets[ix].pos.x == snap.planets[ix].pos.x && ps.planets[ix].vel.x == snap.planets[ix].vel.x {
-				xMatch++
-			}
-			if ps.planets[ix].pos.y == snap.planets[ix].pos.y && ps.planets[ix].vel.y == snap.planets[ix].vel.y {
-				yMatch++
-			}
-			if ps.planets[ix].pos.z == snap.planets[ix].pos.z && ps.planets[ix].vel.z == snap.planets[ix].vel.z {
-				zMatch++
-			}
-		}
-		if xMatch == total {
-			if periods[0] == 0 {
-				set++
-				periods[0] = ps.time
-			}
-		}
-		if yMatch == total {
-			if periods[1] == 0 {
-				set++
-				periods[1] = ps.time
-			}
-		}
-		if zMatch == total {
-			if periods[2] == 0 {
-				set++
-				periods[2] = ps.time
-			}
-		}
-		if set == 3 {
-			break
+	noerr(err)
+
+	ps, err := NewPlanetSystem(newPlanets(scan))
+	noerr(err)
+	log.Printf("Period: %s", ps.Period())
+
+	if *trajectory != "" {
+		file, err := os.Create(*trajectory)
+		noerr(err)
+		noerr(ps.WriteTrajectory(file, *steps))
+		noerr(file.Close())
+	} else {
+		for i := 0; i < *steps; i++ {
+			ps.Tick()
 		}
 	}
-
-	res := lcm(periods[0], lcm(periods[1], periods[2]))
-
-	log.Printf("res: %d", res)
-
-}
-
-func lcm(a, b int) int {
-	return a * b / gcd(a, b)
+	log.Printf("Total energy after %d steps: %d", *steps, ps.Energy())
 }
 
-func gcd(a, b int) int {
-	for b > 0 {
-		a, b = b, a%b
-	}
-	return a
+func lcm(a, b *big.Int) *big.Int {
+	gcd := new(big.Int).GCD(nil, nil, a, b)
+	res := new(big.Int).Quo(a, gcd)
+	return res.Mul(res, b)
 }
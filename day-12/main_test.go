@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func mustSystem(t *testing.T, scan []Vector, opts ...Option) *PlanetSystem {
+	ps, err := NewPlanetSystem(newPlanets(scan), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return ps
+}
+
+func TestReadScan(t *testing.T) {
+	scan, err := ReadScan(strings.NewReader("<x=-1, y=0, z=2>\n\n<x=2, y=-10, z=-7>\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []Vector{{-1, 0, 2}, {2, -10, -7}}
+	if len(scan) != len(want) || !scan[0].Equals(want[0]) || !scan[1].Equals(want[1]) {
+		t.Errorf("ReadScan() = %+v, want %+v", scan, want)
+	}
+}
+
+func TestReadScan_Errors(t *testing.T) {
+	for _, input := range []string{
+		"<x=1, y=2>\n<x=1, y=2, z=3>",
+		"x=1, y=2, z=3",
+		"<x=1,y=2>",
+		"<x=1, y=2, z=3",
+		"<x=1, y=2, z=3> <x=4, y=5, z=6>",
+		"<x=1, y=b, z=3>",
+		"<x=1, y=2, z=3>\n(1, 2, 3)",
+		"<x=1, y=2, z=99999999999999999999>",
+	} {
+		if _, err := ReadScan(strings.NewReader(input)); err == nil {
+			t.Errorf("expected an error for %q", input)
+		}
+	}
+}
+
+func TestPlanetSystem(t *testing.T) {
+	tests := []struct {
+		input  string
+		steps  int
+		energy int
+		period string
+	}{
+		{"INPUT-TST", 10, 179, "2772"},
+		{"INPUT-TST2", 100, 1940, "4686774924"},
+		{"INPUT", 1000, 8044, "362375881472136"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			scan, err := loadScan(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			ps := mustSystem(t, scan)
+			if got := ps.Period().String(); got != tt.period {
+				t.Errorf("Period() = %s, want %s", got, tt.period)
+			}
+			initial := ps.Snapshot()
+			for i := 0; i < tt.steps; i++ {
+				ps.Tick()
+			}
+			if got := ps.Energy(); got != tt.energy {
+				t.Errorf("Energy() after %d steps = %d, want %d", tt.steps, got, tt.energy)
+			}
+			if ps.EqualsTo(initial) {
+				t.Errorf("the system must not be back to the initial state after %d steps", tt.steps)
+			}
+		})
+	}
+}
+
+func TestPlanetSystem_PeriodReturns(t *testing.T) {
+	scan, err := loadScan("INPUT-TST")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ps := mustSystem(t, scan)
+	initial := ps.Snapshot()
+	period := int(ps.Period().Int64())
+	for i := 0; i < period; i++ {
+		ps.Tick()
+		if i < period-1 && ps.EqualsTo(initial) {
+			t.Fatalf("the system returns after %d steps, before the period %d", i+1, period)
+		}
+	}
+	if !ps.EqualsTo(initial) {
+		t.Errorf("the system must be back to the initial state after %d steps", period)
+	}
+}
+
+func TestNewPlanetSystem_Dimensions(t *testing.T) {
+	_, err := NewPlanetSystem(newPlanets([]Vector{{1, 2}, {1, 2, 3}}))
+	if !errors.Is(err, ErrDimensions) {
+		t.Errorf("NewPlanetSystem() error = %v, want %v", err, ErrDimensions)
+	}
+}
+
+func TestPlanetSystem_Dimensions(t *testing.T) {
+	tests := []struct {
+		scan   []Vector
+		period string
+	}{
+		// the x axis of INPUT-TST alone and then twice, it must not change
+		// the period of that axis
+		{[]Vector{{-1}, {2}, {4}, {3}}, "18"},
+		{[]Vector{{-1, -1}, {2, 2}, {4, 4}, {3, 3}}, "18"},
+		// INPUT-TST with an extra w axis copying y
+		{[]Vector{{-1, 0, 2, 0}, {2, -10, -7, -10}, {4, -8, 8, -8}, {3, 5, -1, 5}}, "2772"},
+		{[]Vector{}, "1"},
+	}
+	for _, tt := range tests {
+		ps := mustSystem(t, tt.scan)
+		if got := ps.Period().String(); got != tt.period {
+			t.Errorf("Period() of %v = %s, want %s", tt.scan, got, tt.period)
+		}
+	}
+}
+
+func TestPlanetSystem_Interaction(t *testing.T) {
+	// the pull grows with the distance like a spring, two bodies then
+	// oscillate around their center
+	spring := func(pos, other int) int {
+		return (other - pos) / 2
+	}
+	ps := mustSystem(t, []Vector{{-2, 4}, {2, 0}}, WithInteraction(spring))
+	ps.Tick()
+	want := []Vector{{0, 2}, {0, 2}}
+	for ix, planet := range ps.planets {
+		if !planet.pos.Equals(want[ix]) {
+			t.Errorf("body %d is at %s, want %s", ix, planet.pos, want[ix])
+		}
+	}
+	if got := ps.Period().String(); got != "6" {
+		t.Errorf("Period() = %s, want 6", got)
+	}
+}
+
+func TestPlanetSystem_WriteTrajectory(t *testing.T) {
+	scan, err := loadScan("INPUT-TST")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ps := mustSystem(t, scan)
+	var buf strings.Builder
+	if err := ps.WriteTrajectory(&buf, 2); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1+3*4 {
+		t.Fatalf("got %d lines, want 13:\n%s", len(lines), buf.String())
+	}
+	for ix, want := range map[int]string{
+		0:  "tick,body,x,y,z,vx,vy,vz",
+		1:  "0,0,-1,0,2,0,0,0",
+		5:  "1,0,2,-1,1,3,-1,-1",
+		12: "2,3,1,-4,2,-1,-6,2",
+	} {
+		if lines[ix] != want {
+			t.Errorf("line %d = %q, want %q", ix, lines[ix], want)
+		}
+	}
+	if ps.Time() != 2 {
+		t.Errorf("Time() = %d, want 2", ps.Time())
+	}
+}
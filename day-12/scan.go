@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ReadScan parses the body positions, one per line:
+//
+//	<x=-13, y=-13, z=-13>
+//
+// Any number of axes is accepted as long as all the lines have the same
+// number. Blank lines are skipped.
+func ReadScan(input io.Reader) ([]Vector, error) {
+	res := make([]Vector, 0, 4)
+	scanner := bufio.NewScanner(input)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		v, err := parseVector(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if len(res) > 0 && v.Dims() != res[0].Dims() {
+			return nil, fmt.Errorf("line %d: %w: got %d, want %d", lineNo, ErrDimensions, v.Dims(), res[0].Dims())
+		}
+		res = append(res, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+var coordRe = regexp.MustCompile(`^[a-z][a-z0-9]*=(-?\d+)$`)
+
+func parseVector(s string) (Vector, error) {
+	if !strings.HasPrefix(s, "<") || !strings.HasSuffix(s, ">") {
+		return nil, fmt.Errorf("want <x=X, y=Y, z=Z>, got %q", s)
+	}
+	chunks := strings.Split(s[1:len(s)-1], ", ")
+	v := make(Vector, 0, len(chunks))
+	for _, chunk := range chunks {
+		m := coordRe.FindStringSubmatch(chunk)
+		if m == nil {
+			return nil, fmt.Errorf("invalid coordinate %q in %q", chunk, s)
+		}
+		c, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, err
+		}
+		v = append(v, c)
+	}
+	return v, nil
+}
+
+func loadScan(path string) ([]Vector, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadScan(file)
+}
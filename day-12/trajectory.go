@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// WriteTrajectory simulates the given number of ticks and writes the state of
+// every body in CSV, one row per body per tick. The current state goes first
+// as its tick. The header names the axes like the scan does:
+//
+//	tick,body,x,y,z,vx,vy,vz
+func (ps *PlanetSystem) WriteTrajectory(w io.Writer, ticks int) error {
+	out := csv.NewWriter(w)
+	header := []string{"tick", "body"}
+	for ix := 0; ix < ps.dims; ix++ {
+		header = append(header, axisName(ix))
+	}
+	for ix := 0; ix < ps.dims; ix++ {
+		header = append(header, "v"+axisName(ix))
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+
+	row := make([]string, len(header))
+	for i := 0; ; i++ {
+		for body, planet := range ps.planets {
+			row = row[:0]
+			row = append(row, strconv.Itoa(ps.time), strconv.Itoa(body))
+			for _, c := range planet.pos {
+				row = append(row, strconv.Itoa(c))
+			}
+			for _, c := range planet.vel {
+				row = append(row, strconv.Itoa(c))
+			}
+			if err := out.Write(row); err != nil {
+				return err
+			}
+		}
+		if i == ticks {
+			break
+		}
+		ps.Tick()
+	}
+	out.Flush()
+	return out.Error()
+}
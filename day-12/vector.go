@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Vector is a point or a velocity in a space of any number of dimensions.
+type Vector []int
+
+func NewVector(dims int) Vector {
+	return make(Vector, dims)
+}
+
+func (v Vector) Dims() int {
+	return len(v)
+}
+
+func (v Vector) Add(v2 Vector) Vector {
+	res := make(Vector, len(v))
+	for ix := range v {
+		res[ix] = v[ix] + v2[ix]
+	}
+	return res
+}
+
+func (v Vector) Equals(v2 Vector) bool {
+	if len(v) != len(v2) {
+		return false
+	}
+	for ix := range v {
+		if v[ix] != v2[ix] {
+			return false
+		}
+	}
+	return true
+}
+
+// Norm1 is the sum of the absolute values of the components.
+func (v Vector) Norm1() int {
+	res := 0
+	for _, c := range v {
+		res += abs(c)
+	}
+	return res
+}
+
+func (v Vector) Clone() Vector {
+	return append(Vector{}, v...)
+}
+
+// axisName names the first axes after the puzzle, x, y, z and w, the others
+// are numbered.
+func axisName(ix int) string {
+	if ix < 4 {
+		return string("xyzw"[ix])
+	}
+	return fmt.Sprintf("d%d", ix)
+}
+
+func (v Vector) String() string {
+	chunks := make([]string, 0, len(v))
+	for ix, c := range v {
+		chunks = append(chunks, fmt.Sprintf("%s=%d", axisName(ix), c))
+	}
+	return "<" + strings.Join(chunks, ", ") + ">"
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"math/big"
+)
+
+// BigFuelResult is FuelResult for the math/big mode.
+type BigFuelResult struct {
+	Fuel *big.Int
+	Ore  *big.Int
+	Left map[string]*big.Int
+}
+
+var bigOne = big.NewInt(1)
+
+// ceilDivBig divides rounding up, a and b are positive.
+func ceilDivBig(a, b *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+	if r.Sign() != 0 {
+		q.Add(q, bigOne)
+	}
+	return q
+}
+
+// productionBig is production without the overflow limits.
+func productionBig(reactions map[string]Chemical, order []string, fuel *big.Int) (map[string]*big.Int, map[string]*big.Int) {
+	need := map[string]*big.Int{FUEL: new(big.Int).Set(fuel)}
+	made := make(map[string]*big.Int)
+	for _, handle := range order {
+		if handle == ORE {
+			continue
+		}
+		quant, ok := need[handle]
+		if !ok || quant.Sign() <= 0 {
+			continue
+		}
+		base := reactions[handle]
+		mult := ceilDivBig(quant, big.NewInt(int64(base.quant)))
+		made[handle] = new(big.Int).Mul(mult, big.NewInt(int64(base.quant)))
+		for _, comp := range base.comps {
+			if _, ok := need[comp.handle]; !ok {
+				need[comp.handle] = new(big.Int)
+			}
+			quant := new(big.Int).Mul(mult, big.NewInt(int64(comp.quant)))
+			need[comp.handle].Add(need[comp.handle], quant)
+		}
+	}
+	if _, ok := need[ORE]; !ok {
+		need[ORE] = new(big.Int)
+	}
+	return need, made
+}
+
+// OreForBig computes the ore needed to produce the given amount of fuel and
+// the surplus of every chemical produced on the way.
+func OreForBig(reactions map[string]Chemical, fuel *big.Int) (*big.Int, map[string]*big.Int) {
+	return oreForBig(reactions, topoOrder(reactions), fuel)
+}
+
+func oreForBig(reactions map[string]Chemical, order []string, fuel *big.Int) (*big.Int, map[string]*big.Int) {
+	need, made := productionBig(reactions, order, fuel)
+	left := make(map[string]*big.Int)
+	for handle, quant := range made {
+		if rem := new(big.Int).Sub(quant, need[handle]); rem.Sign() > 0 {
+			left[handle] = rem
+		}
+	}
+	return need[ORE], left
+}
+
+// MaxFuelBig is MaxFuel for ore budgets of any size.
+func MaxFuelBig(reactions map[string]Chemical, ore *big.Int) BigFuelResult {
+	order := topoOrder(reactions)
+	fits := func(fuel *big.Int) bool {
+		need, _ := oreForBig(reactions, order, fuel)
+		return need.Cmp(ore) <= 0
+	}
+	lo, hi := new(big.Int), big.NewInt(1)
+	for fits(hi) {
+		lo.Set(hi)
+		hi.Lsh(hi, 1)
+	}
+	// fits(lo) holds, fits(hi) does not
+	gap, mid := new(big.Int), new(big.Int)
+	for gap.Sub(hi, lo).Cmp(bigOne) > 0 {
+		mid.Rsh(gap, 1)
+		mid.Add(mid, lo)
+		if fits(mid) {
+			lo.Set(mid)
+		} else {
+			hi.Set(mid)
+		}
+	}
+	spent, left := oreForBig(reactions, order, lo)
+	if rem := new(big.Int).Sub(ore, spent); rem.Sign() > 0 {
+		left[ORE] = rem
+	}
+	return BigFuelResult{
+		Fuel: lo,
+		Ore:  spent,
+		Left: left,
+	}
+}
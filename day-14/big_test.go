@@ -0,0 +1,107 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestCeilDiv(t *testing.T) {
+	tests := []struct {
+		a, b, want int
+	}{
+		{1, 1, 1},
+		{7, 10, 1},
+		{10, 10, 1},
+		{11, 10, 2},
+		{1000000000000, 7, 142857142858},
+		// float64 has 53 bits of mantissa, so a float division rounds
+		// 2^53+1 down to 2^53 and loses the remainder
+		{1<<53 + 1, 2, 1<<52 + 1},
+		{1<<62 + 3, 1 << 61, 3},
+		{math.MaxInt, 1, math.MaxInt},
+		{math.MaxInt, math.MaxInt, 1},
+	}
+	for _, tt := range tests {
+		if got := ceilDiv(tt.a, tt.b); got != tt.want {
+			t.Errorf("ceilDiv(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		got := ceilDivBig(big.NewInt(int64(tt.a)), big.NewInt(int64(tt.b)))
+		if !got.IsInt64() || got.Int64() != int64(tt.want) {
+			t.Errorf("ceilDivBig(%d, %d) = %s, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestOreFor_Overflow(t *testing.T) {
+	reactions := mustLoad(t, "INPUT-TST3")
+	if _, _, err := oreFor(reactions, topoOrder(reactions), math.MaxInt/1000); err != ErrOverflow {
+		t.Errorf("oreFor() error = %v, want %v", err, ErrOverflow)
+	}
+}
+
+// Both modes must agree around the 10^12 budget of the puzzle and above it as
+// long as int holds.
+func TestMaxFuelBig(t *testing.T) {
+	for _, input := range []string{"INPUT-TST3", "INPUT-TST4", "INPUT-TST5", "INPUT"} {
+		reactions := mustLoad(t, input)
+		for _, ore := range []int{999999999999, 1000000000000, 1000000000001, 1 << 40, 1 << 50, 1e15, 1e18} {
+			res := MaxFuel(reactions, ore)
+			bres := MaxFuelBig(reactions, big.NewInt(int64(ore)))
+			if !bres.Fuel.IsInt64() || bres.Fuel.Int64() != int64(res.Fuel) {
+				t.Errorf("%s, %d ORE: MaxFuelBig() = %s, MaxFuel() = %d", input, ore, bres.Fuel, res.Fuel)
+			}
+			if bres.Ore.Int64() != int64(res.Ore) {
+				t.Errorf("%s, %d ORE: MaxFuelBig() spent %s, MaxFuel() spent %d", input, ore, bres.Ore, res.Ore)
+			}
+			for handle, left := range res.Left {
+				if bleft := bres.Left[handle]; bleft == nil || bleft.Int64() != int64(left) {
+					t.Errorf("%s, %d ORE: %s left %v, want %d", input, ore, handle, bleft, left)
+				}
+			}
+		}
+	}
+}
+
+// The doubling used to overflow and spin forever with budgets close to the
+// int limit.
+func TestMaxFuel_HugeBudget(t *testing.T) {
+	reactions, err := ReadReactions(strings.NewReader("1 ORE => 1 FUEL\n"))
+	if err != nil {
+		t.Fatalf("ReadReactions() failed: %s", err)
+	}
+	for _, ore := range []int{1 << 62, math.MaxInt/2 + 1, math.MaxInt - 1, math.MaxInt} {
+		if res := MaxFuel(reactions, ore); res.Fuel != ore || res.Ore != ore {
+			t.Errorf("%d ORE: got %d FUEL for %d ORE, want %d for %d", ore, res.Fuel, res.Ore, ore, ore)
+		}
+	}
+
+	// the budget is enough for more fuel than int holds, the batches of 10
+	// must not overflow either
+	reactions, err = ReadReactions(strings.NewReader("1 ORE => 10 FUEL\n"))
+	if err != nil {
+		t.Fatalf("ReadReactions() failed: %s", err)
+	}
+	if res, want := MaxFuel(reactions, math.MaxInt), math.MaxInt/10*10; res.Fuel != want {
+		t.Errorf("got %d FUEL, want it capped at %d", res.Fuel, want)
+	}
+}
+
+func TestMaxFuelBig_Huge(t *testing.T) {
+	reactions := mustLoad(t, "INPUT-TST3")
+	ore, _ := new(big.Int).SetString("1000000000000000000000000000000", 10)
+	res := MaxFuelBig(reactions, ore)
+	spent, _ := OreForBig(reactions, res.Fuel)
+	if spent.Cmp(ore) > 0 || spent.Cmp(res.Ore) != 0 {
+		t.Errorf("%s FUEL takes %s ORE, budget is %s", res.Fuel, spent, ore)
+	}
+	more, _ := OreForBig(reactions, new(big.Int).Add(res.Fuel, bigOne))
+	if more.Cmp(ore) <= 0 {
+		t.Errorf("%s FUEL more fits into the budget", res.Fuel)
+	}
+	// 13312 ORE per FUEL is the upper bound, the surplus makes it cheaper
+	if min := new(big.Int).Quo(ore, big.NewInt(13312)); res.Fuel.Cmp(min) < 0 {
+		t.Errorf("MaxFuelBig() = %s, want at least %s", res.Fuel, min)
+	}
+}
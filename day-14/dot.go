@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Flow is the amount of a chemical going through the nanofactory: Wasted is
+// the part produced but never consumed.
+type Flow struct {
+	Produced int
+	Consumed int
+	Wasted   int
+}
+
+// Flows returns the flow of every chemical taking part in the production of
+// the given amount of fuel. ORE is taken as produced exactly in the amount
+// consumed.
+func Flows(reactions map[string]Chemical, fuel int) (map[string]Flow, error) {
+	need, made, err := production(reactions, topoOrder(reactions), fuel)
+	if err != nil {
+		return nil, err
+	}
+	made[ORE] = need[ORE]
+	res := make(map[string]Flow, len(made))
+	for handle, produced := range made {
+		res[handle] = Flow{
+			Produced: produced,
+			Consumed: need[handle],
+			Wasted:   produced - need[handle],
+		}
+	}
+	return res, nil
+}
+
+// WriteDOT exports the reaction graph in Graphviz notation. Every edge goes
+// from a component to the chemical it is used for and carries the quantity
+// one reaction takes. With fuel > 0 the nodes are annotated with the flows
+// for that amount of fuel. Render it with `dot -Tsvg`.
+func WriteDOT(w io.Writer, reactions map[string]Chemical, fuel int) error {
+	var flows map[string]Flow
+	if fuel > 0 {
+		var err error
+		if flows, err = Flows(reactions, fuel); err != nil {
+			return err
+		}
+	}
+	handles := make([]string, 0, len(reactions)+1)
+	handles = append(handles, ORE)
+	for handle := range reactions {
+		handles = append(handles, handle)
+	}
+	sort.Strings(handles[1:])
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "digraph reactions {")
+	fmt.Fprintln(out, "\trankdir=BT;")
+	fmt.Fprintln(out, "\tnode [shape=box];")
+	for _, handle := range handles {
+		label := handle
+		if handle != ORE {
+			label += fmt.Sprintf("\\nbatch: %d", reactions[handle].quant)
+		}
+		if flows != nil {
+			f := flows[handle]
+			label += fmt.Sprintf("\\nproduced: %d\\nconsumed: %d\\nwasted: %d", f.Produced, f.Consumed, f.Wasted)
+		}
+		attrs := ""
+		switch {
+		case handle == ORE || handle == FUEL:
+			attrs = ", style=bold"
+		case flows != nil && flows[handle].Wasted > 0:
+			attrs = ", color=red"
+		}
+		fmt.Fprintf(out, "\t%q [label=\"%s\"%s];\n", handle, label, attrs)
+	}
+	for _, handle := range handles[1:] {
+		for _, comp := range reactions[handle].comps {
+			fmt.Fprintf(out, "\t%q -> %q [label=\"%d\"];\n", comp.handle, handle, comp.quant)
+		}
+	}
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
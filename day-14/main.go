@@ -1,12 +1,10 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"log"
-	"math"
+	"math/big"
 	"os"
-	"strconv"
-	"strings"
 )
 
 type Chemical struct {
@@ -21,104 +19,53 @@ func noerr(err error) {
 	}
 }
 
-func parseReaction(s string) Chemical {
-	chunks := strings.SplitN(s, " => ", 2)
-	chem := parseChemicals(chunks[1])[0]
-	comps := parseChemicals(chunks[0])
-	chem.comps = comps
-	return chem
-}
-
-func parseChemicals(s string) []Chemical {
-	res := make([]Chemical, 0, 1)
-	chunks := strings.Split(s, ", ")
-	for _, ch := range chunks {
-		res = append(res, parseChemical(ch))
-	}
-	return res
-}
-
-func parseChemical(s string) Chemical {
-	chunks := strings.SplitN(s, " ", 2)
-	quant, err := strconv.Atoi(chunks[0])
-	handle := chunks[1]
-	noerr(err)
-	return Chemical{
-		quant:  quant,
-		handle: handle,
-	}
-}
-
 const (
 	ORE = "ORE"
 )
 
-func min(a, b int) int {
-	if a < b {
-		return a
+func loadReactions(path string) (map[string]Chemical, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
-	return b
+	defer file.Close()
+	return ReadReactions(file)
 }
 
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
+func main() {
+	input := flag.String("input", "INPUT", "reactions file")
+	dot := flag.Bool("dot", false, "print the reaction graph in DOT notation and exit")
+	fuel := flag.Int("fuel", 0, "annotate the DOT graph with the flows for that much FUEL")
+	oreBudget := flag.String("ore", "1000000000000", "ORE available for part two")
+	useBig := flag.Bool("big", false, "use math/big for part two, implied when -ore overflows int")
+	flag.Parse()
 
-func computeORE(reactions map[string]Chemical, left map[string]int, handle string, quant int) int {
-	if handle == ORE {
-		if quant > left[ORE] {
-			return -1
-		}
-		left[ORE] -= quant
-		return quant
+	ore, ok := new(big.Int).SetString(*oreBudget, 10)
+	if !ok || ore.Sign() < 0 {
+		log.Fatalf("Invalid ORE amount: %q", *oreBudget)
 	}
-	rem := left[handle]
-	tmp := quant - min(rem, quant)
-	left[handle] = max(left[handle]-quant, 0)
-	quant = tmp
-	ore := 0
-	if quant > 0 {
-		base := reactions[handle]
-		mult := int(math.Ceil(float64(quant) / float64(base.quant)))
-		for _, comp := range base.comps {
-			compore := computeORE(reactions, left, comp.handle, mult*comp.quant)
-			if compore < 0 {
-				return -1
-			}
-			ore += compore
-		}
-		left[handle] = mult*base.quant - quant
-	}
-	return ore
-}
 
-func main() {
-	file, err := os.Open("INPUT")
+	reactions, err := loadReactions(*input)
 	noerr(err)
 
-	reactions := make(map[string]Chemical)
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		s := strings.Trim(scanner.Text(), "\n\t\r")
-		chem := parseReaction(s)
-		reactions[chem.handle] = chem
+	if *dot {
+		noerr(WriteDOT(os.Stdout, reactions, *fuel))
+		return
 	}
 
 	log.Printf("Reactions: %+v", reactions)
 
-	left := make(map[string]int)
-	left[ORE] = 1000000000000
-	cnt := 0
-	for {
-		ore := computeORE(reactions, left, "FUEL", 1)
-		if ore < 0 {
-			break
-		}
-		cnt++
+	perFuel, _, err := oreFor(reactions, topoOrder(reactions), 1)
+	noerr(err)
+	log.Printf("ORE for 1 FUEL: %d", perFuel)
+
+	if *useBig || !ore.IsInt64() {
+		res := MaxFuelBig(reactions, ore)
+		log.Printf("Total count: %s", res.Fuel)
+		log.Printf("ORE spent: %s, left: %v", res.Ore, res.Left)
+		return
 	}
-	log.Printf("Total count: %d", cnt)
+	res := MaxFuel(reactions, int(ore.Int64()))
+	log.Printf("Total count: %d", res.Fuel)
+	log.Printf("ORE spent: %d, left: %+v", res.Ore, res.Left)
 }
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"math"
+)
+
+const (
+	FUEL = "FUEL"
+)
+
+var (
+	ErrOverflow = errors.New("quantity overflows int, use the math/big mode")
+)
+
+// FuelResult is the outcome of a MaxFuel run: the fuel produced, the ore
+// spent on it and the surplus of every chemical left after the reactions,
+// the unspent ore included.
+type FuelResult struct {
+	Fuel int
+	Ore  int
+	Left map[string]int
+}
+
+// topoOrder sorts the chemicals so that every chemical goes before all of its
+// components: once a chemical is reached, all of its consumers have already
+// added their demand. FUEL goes first and ORE goes last.
+func topoOrder(reactions map[string]Chemical) []string {
+	consumers := make(map[string]int)
+	for _, chem := range reactions {
+		for _, comp := range chem.comps {
+			consumers[comp.handle]++
+		}
+	}
+	order := make([]string, 0, len(reactions)+1)
+	queue := make([]string, 0, 1)
+	for handle := range reactions {
+		if consumers[handle] == 0 {
+			queue = append(queue, handle)
+		}
+	}
+	var handle string
+	for len(queue) > 0 {
+		handle, queue = queue[0], queue[1:]
+		order = append(order, handle)
+		for _, comp := range reactions[handle].comps {
+			consumers[comp.handle]--
+			if consumers[comp.handle] == 0 {
+				queue = append(queue, comp.handle)
+			}
+		}
+	}
+	return order
+}
+
+// ceilDiv divides rounding up, a and b are positive.
+func ceilDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 {
+		q++
+	}
+	return q
+}
+
+func mulChecked(a, b int) (int, error) {
+	if a != 0 && b > math.MaxInt/a {
+		return 0, ErrOverflow
+	}
+	return a * b, nil
+}
+
+func addChecked(a, b int) (int, error) {
+	if a > math.MaxInt-b {
+		return 0, ErrOverflow
+	}
+	return a + b, nil
+}
+
+// production runs the reactions for the given amount of fuel in a single
+// pass over the chemicals in topological order. It returns the total demand
+// for every chemical and the amount actually produced, which is rounded up to
+// whole reactions. ORE is demanded but never produced.
+func production(reactions map[string]Chemical, order []string, fuel int) (map[string]int, map[string]int, error) {
+	need := map[string]int{FUEL: fuel}
+	made := make(map[string]int)
+	for _, handle := range order {
+		if handle == ORE {
+			continue
+		}
+		quant := need[handle]
+		if quant <= 0 {
+			continue
+		}
+		base := reactions[handle]
+		mult := ceilDiv(quant, base.quant)
+		var err error
+		if made[handle], err = mulChecked(mult, base.quant); err != nil {
+			return nil, nil, err
+		}
+		for _, comp := range base.comps {
+			quant, err := mulChecked(mult, comp.quant)
+			if err != nil {
+				return nil, nil, err
+			}
+			if need[comp.handle], err = addChecked(need[comp.handle], quant); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return need, made, nil
+}
+
+// oreFor computes the ore needed to produce the given amount of fuel and the
+// surplus of every chemical produced on the way.
+func oreFor(reactions map[string]Chemical, order []string, fuel int) (int, map[string]int, error) {
+	need, made, err := production(reactions, order, fuel)
+	if err != nil {
+		return 0, nil, err
+	}
+	left := make(map[string]int)
+	for handle, quant := range made {
+		if rem := quant - need[handle]; rem > 0 {
+			left[handle] = rem
+		}
+	}
+	return need[ORE], left, nil
+}
+
+// MaxFuel finds the max amount of fuel the given ore is enough for. The upper
+// bound is found by doubling, then the answer is binary searched. The doubling
+// stops at math.MaxInt and an amount of fuel whose production overflows int
+// does not fit. So with budgets close to the int limit the result is bounded
+// by the int arithmetic rather than by the ore, MaxFuelBig has no such limit.
+func MaxFuel(reactions map[string]Chemical, ore int) FuelResult {
+	order := topoOrder(reactions)
+	fits := func(fuel int) bool {
+		need, _, err := oreFor(reactions, order, fuel)
+		return err == nil && need <= ore
+	}
+	lo, hi := 0, 1
+	for fits(hi) {
+		lo = hi
+		if hi > math.MaxInt/2 {
+			hi = math.MaxInt
+			break
+		}
+		hi *= 2
+	}
+	if hi == math.MaxInt && fits(hi) {
+		lo = hi
+	}
+	// fits(lo) holds, fits(hi) does not unless both are math.MaxInt
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if fits(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	// lo fits, so it does not overflow
+	spent, left, _ := oreFor(reactions, order, lo)
+	if ore > spent {
+		left[ORE] = ore - spent
+	}
+	return FuelResult{
+		Fuel: lo,
+		Ore:  spent,
+		Left: left,
+	}
+}
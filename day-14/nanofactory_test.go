@@ -0,0 +1,119 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOreFor(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"INPUT-TST", 31},
+		{"INPUT-TST2", 165},
+		{"INPUT-TST3", 13312},
+		{"INPUT-TST4", 180697},
+		{"INPUT-TST5", 2210736},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			reactions := mustLoad(t, tt.input)
+			got, _, err := oreFor(reactions, topoOrder(reactions), 1)
+			if err != nil {
+				t.Fatalf("oreFor() failed: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("oreFor() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxFuel(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"INPUT-TST3", 82892753},
+		{"INPUT-TST4", 5586022},
+		{"INPUT-TST5", 460664},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			reactions := mustLoad(t, tt.input)
+			res := MaxFuel(reactions, 1000000000000)
+			if res.Fuel != tt.want {
+				t.Errorf("MaxFuel() = %d, want %d", res.Fuel, tt.want)
+			}
+			if res.Ore+res.Left[ORE] != 1000000000000 {
+				t.Errorf("ORE spent %d and left %d do not add up", res.Ore, res.Left[ORE])
+			}
+			more, _, _ := oreFor(reactions, topoOrder(reactions), res.Fuel+1)
+			if more <= 1000000000000 {
+				t.Errorf("%d FUEL takes %d ORE, MaxFuel is not max", res.Fuel+1, more)
+			}
+		})
+	}
+}
+
+func TestMaxFuel_NotEnoughOre(t *testing.T) {
+	reactions := mustLoad(t, "INPUT-TST")
+	res := MaxFuel(reactions, 30)
+	if res.Fuel != 0 || res.Ore != 0 || res.Left[ORE] != 30 {
+		t.Errorf("MaxFuel() = %+v, want no fuel and all the ORE left", res)
+	}
+}
+
+func TestFlows(t *testing.T) {
+	reactions := mustLoad(t, "INPUT-TST")
+	flows, err := Flows(reactions, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := map[string]Flow{
+		ORE:  {Produced: 31, Consumed: 31},
+		"A":  {Produced: 30, Consumed: 28, Wasted: 2},
+		"B":  {Produced: 1, Consumed: 1},
+		"C":  {Produced: 1, Consumed: 1},
+		"D":  {Produced: 1, Consumed: 1},
+		"E":  {Produced: 1, Consumed: 1},
+		FUEL: {Produced: 1, Consumed: 1},
+	}
+	for handle, f := range want {
+		if flows[handle] != f {
+			t.Errorf("flow of %s = %+v, want %+v", handle, flows[handle], f)
+		}
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	reactions := mustLoad(t, "INPUT-TST")
+	var buf strings.Builder
+	if err := WriteDOT(&buf, reactions, 0); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"digraph reactions {",
+		`"ORE" -> "A" [label="10"];`,
+		`"A" -> "C" [label="7"];`,
+		`"A" [label="A\nbatch: 10"];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("%q is missing in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "wasted") {
+		t.Errorf("no flows expected without fuel:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := WriteDOT(&buf, reactions, 1); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := `"A" [label="A\nbatch: 10\nproduced: 30\nconsumed: 28\nwasted: 2", color=red];`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("%q is missing in:\n%s", want, buf.String())
+	}
+}
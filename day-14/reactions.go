@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMalformed     = errors.New("malformed reaction")
+	ErrCycle         = errors.New("reaction cycle")
+	ErrNoProducer    = errors.New("no reaction produces the chemical")
+	ErrManyProducers = errors.New("chemical is produced by more than one reaction")
+	ErrUnreachable   = errors.New("chemical is not needed for FUEL")
+)
+
+// ReactionError is a problem found in the reaction list. Line is 0 for the
+// problems which do not belong to a single line, like a missing FUEL
+// reaction.
+type ReactionError struct {
+	Line     int
+	Chemical string
+	Err      error
+	Detail   string
+}
+
+func (e *ReactionError) Error() string {
+	var buf strings.Builder
+	if e.Line > 0 {
+		fmt.Fprintf(&buf, "line %d: ", e.Line)
+	}
+	buf.WriteString(e.Err.Error())
+	if e.Chemical != "" {
+		fmt.Fprintf(&buf, ": %s", e.Chemical)
+	}
+	if e.Detail != "" {
+		fmt.Fprintf(&buf, " (%s)", e.Detail)
+	}
+	return buf.String()
+}
+
+func (e *ReactionError) Unwrap() error {
+	return e.Err
+}
+
+// ReactionErrors holds all the problems found in the reaction list ordered
+// by line, the ones without a line go last.
+type ReactionErrors []*ReactionError
+
+func (es ReactionErrors) Error() string {
+	msgs := make([]string, 0, len(es))
+	for _, e := range es {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Is makes errors.Is match any of the collected errors.
+func (es ReactionErrors) Is(target error) bool {
+	for _, e := range es {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+type reactionLine struct {
+	line int
+	chem Chemical
+}
+
+func parseReaction(s string) (Chemical, error) {
+	chunks := strings.SplitN(s, " => ", 2)
+	if len(chunks) != 2 {
+		return Chemical{}, fmt.Errorf("no ` => ` in %q", s)
+	}
+	chems, err := parseChemicals(chunks[1])
+	if err != nil {
+		return Chemical{}, err
+	}
+	if len(chems) != 1 {
+		return Chemical{}, fmt.Errorf("%d chemicals produced, want 1", len(chems))
+	}
+	chem := chems[0]
+	comps, err := parseChemicals(chunks[0])
+	if err != nil {
+		return Chemical{}, err
+	}
+	chem.comps = comps
+	return chem, nil
+}
+
+func parseChemicals(s string) ([]Chemical, error) {
+	res := make([]Chemical, 0, 1)
+	chunks := strings.Split(s, ", ")
+	for _, ch := range chunks {
+		chem, err := parseChemical(ch)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, chem)
+	}
+	return res, nil
+}
+
+func parseChemical(s string) (Chemical, error) {
+	chunks := strings.Split(strings.TrimSpace(s), " ")
+	if len(chunks) != 2 {
+		return Chemical{}, fmt.Errorf("want `<quantity> <chemical>`, got %q", s)
+	}
+	quant, err := strconv.Atoi(chunks[0])
+	if err != nil || quant <= 0 {
+		return Chemical{}, fmt.Errorf("invalid quantity %q", chunks[0])
+	}
+	handle := chunks[1]
+	for _, r := range handle {
+		if r < 'A' || r > 'Z' {
+			return Chemical{}, fmt.Errorf("invalid chemical %q", handle)
+		}
+	}
+	return Chemical{
+		quant:  quant,
+		handle: handle,
+	}, nil
+}
+
+// ReadReactions parses and validates the reaction list, blank lines are
+// skipped. All the problems found are returned at once as ReactionErrors.
+func ReadReactions(input io.Reader) (map[string]Chemical, error) {
+	lines := make([]reactionLine, 0, 1)
+	var errs ReactionErrors
+	scanner := bufio.NewScanner(input)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		s := strings.Trim(scanner.Text(), "\n\t\r ")
+		if s == "" {
+			continue
+		}
+		chem, err := parseReaction(s)
+		if err != nil {
+			errs = append(errs, &ReactionError{Line: lineNo, Err: ErrMalformed, Detail: err.Error()})
+			continue
+		}
+		lines = append(lines, reactionLine{line: lineNo, chem: chem})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	errs = append(errs, validateReactions(lines)...)
+	if len(errs) > 0 {
+		// the errors without a line go last
+		sort.SliceStable(errs, func(i, j int) bool {
+			li, lj := errs[i].Line, errs[j].Line
+			return li != 0 && (lj == 0 || li < lj)
+		})
+		return nil, errs
+	}
+
+	reactions := make(map[string]Chemical, len(lines))
+	for _, l := range lines {
+		reactions[l.chem.handle] = l.chem
+	}
+	return reactions, nil
+}
+
+// validateReactions checks that every chemical but ORE has exactly one
+// producer, there are no cycles and every reaction contributes to FUEL.
+func validateReactions(lines []reactionLine) ReactionErrors {
+	var errs ReactionErrors
+	producers := make(map[string]reactionLine)
+	for _, l := range lines {
+		handle := l.chem.handle
+		if handle == ORE {
+			errs = append(errs, &ReactionError{Line: l.line, Err: ErrMalformed, Detail: "ORE can not be produced"})
+			continue
+		}
+		if first, ok := producers[handle]; ok {
+			errs = append(errs, &ReactionError{
+				Line:     l.line,
+				Chemical: handle,
+				Err:      ErrManyProducers,
+				Detail:   fmt.Sprintf("first produced at line %d", first.line),
+			})
+			continue
+		}
+		producers[handle] = l
+	}
+
+	for _, l := range lines {
+		for _, comp := range l.chem.comps {
+			if _, ok := producers[comp.handle]; !ok && comp.handle != ORE {
+				errs = append(errs, &ReactionError{Line: l.line, Chemical: comp.handle, Err: ErrNoProducer})
+			}
+		}
+	}
+	fuel, ok := producers[FUEL]
+	if !ok {
+		errs = append(errs, &ReactionError{Chemical: FUEL, Err: ErrNoProducer})
+	}
+
+	// a DFS from every reaction, a grey chemical met again closes a cycle
+	const (
+		white = iota
+		grey
+		black
+	)
+	colors := make(map[string]int)
+	stack := make([]string, 0, 1)
+	var visit func(l reactionLine)
+	visit = func(l reactionLine) {
+		handle := l.chem.handle
+		colors[handle] = grey
+		stack = append(stack, handle)
+		for _, comp := range l.chem.comps {
+			next, ok := producers[comp.handle]
+			if !ok {
+				continue
+			}
+			switch colors[comp.handle] {
+			case white:
+				visit(next)
+			case grey:
+				ix := len(stack) - 1
+				for stack[ix] != comp.handle {
+					ix--
+				}
+				cycle := append(append([]string{}, stack[ix:]...), comp.handle)
+				errs = append(errs, &ReactionError{
+					Line:     l.line,
+					Chemical: comp.handle,
+					Err:      ErrCycle,
+					Detail:   strings.Join(cycle, " -> "),
+				})
+			}
+		}
+		stack = stack[:len(stack)-1]
+		colors[handle] = black
+	}
+	if ok {
+		visit(fuel)
+	}
+	for _, l := range lines {
+		// everything not visited from FUEL is unreachable
+		if ok && producers[l.chem.handle].line == l.line && colors[l.chem.handle] == white {
+			errs = append(errs, &ReactionError{Line: l.line, Chemical: l.chem.handle, Err: ErrUnreachable})
+		}
+	}
+	// the unreachable reactions might have cycles too
+	for _, l := range lines {
+		if producers[l.chem.handle].line == l.line && colors[l.chem.handle] == white {
+			visit(l)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func mustLoad(t testing.TB, path string) map[string]Chemical {
+	reactions, err := loadReactions(path)
+	if err != nil {
+		t.Fatalf("failed to load %s: %s", path, err)
+	}
+	return reactions
+}
+
+func TestReadReactions(t *testing.T) {
+	reactions, err := ReadReactions(strings.NewReader("10 ORE => 10 A\n\n1 ORE => 1 B\n7 A, 1 B => 1 FUEL\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	fuel := reactions[FUEL]
+	if fuel.quant != 1 || len(fuel.comps) != 2 || fuel.comps[0].handle != "A" || fuel.comps[0].quant != 7 {
+		t.Errorf("unexpected FUEL reaction: %+v", fuel)
+	}
+}
+
+func TestReadReactions_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []*ReactionError
+	}{
+		{
+			name:  "malformed",
+			input: "1 ORE => 1 A\n1 A -> 1 FUEL\nx ORE => 1 B\n1 ORE => 1 b\n1 ORE => 0 C\n1 ORE => 1 D, 1 E",
+			want: []*ReactionError{
+				{Line: 2, Err: ErrMalformed},
+				{Line: 3, Err: ErrMalformed},
+				{Line: 4, Err: ErrMalformed},
+				{Line: 5, Err: ErrMalformed},
+				{Line: 6, Err: ErrMalformed},
+				{Line: 0, Chemical: FUEL, Err: ErrNoProducer},
+			},
+		},
+		{
+			name:  "missing producer",
+			input: "1 ORE => 1 A\n1 A, 2 B => 1 FUEL",
+			want: []*ReactionError{
+				{Line: 2, Chemical: "B", Err: ErrNoProducer},
+			},
+		},
+		{
+			name:  "many producers",
+			input: "1 ORE => 1 A\n2 ORE => 1 A\n1 A => 1 FUEL\n1 ORE => 1 ORE",
+			want: []*ReactionError{
+				{Line: 2, Chemical: "A", Err: ErrManyProducers},
+				{Line: 4, Err: ErrMalformed},
+			},
+		},
+		{
+			name:  "unreachable",
+			input: "1 ORE => 1 A\n1 A => 1 FUEL\n1 ORE => 1 B\n1 B => 1 C",
+			want: []*ReactionError{
+				{Line: 3, Chemical: "B", Err: ErrUnreachable},
+				{Line: 4, Chemical: "C", Err: ErrUnreachable},
+			},
+		},
+		{
+			name:  "cycle",
+			input: "1 C, 1 ORE => 1 A\n1 A => 1 B\n1 B => 1 C\n1 A => 1 FUEL",
+			want: []*ReactionError{
+				{Line: 2, Chemical: "A", Err: ErrCycle},
+			},
+		},
+		{
+			name:  "unreachable cycle",
+			input: "1 ORE => 1 FUEL\n1 B => 1 A\n1 A => 1 B",
+			want: []*ReactionError{
+				{Line: 2, Chemical: "A", Err: ErrUnreachable},
+				{Line: 3, Chemical: "B", Err: ErrUnreachable},
+				{Line: 3, Chemical: "A", Err: ErrCycle},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadReactions(strings.NewReader(tt.input))
+			var errs ReactionErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("ReadReactions() error = %v, want ReactionErrors", err)
+			}
+			if len(errs) != len(tt.want) {
+				t.Fatalf("got %d errors, want %d:\n%s", len(errs), len(tt.want), err)
+			}
+			for ix, want := range tt.want {
+				got := errs[ix]
+				if got.Line != want.Line || got.Chemical != want.Chemical || got.Err != want.Err {
+					t.Errorf("error %d = %q, want line %d, %s, %q", ix, got, want.Line, want.Chemical, want.Err)
+				}
+			}
+			if !errors.Is(err, tt.want[0].Err) {
+				t.Errorf("errors.Is(err, %v) = false", tt.want[0].Err)
+			}
+		})
+	}
+}
+
+func TestReadReactions_CycleDetail(t *testing.T) {
+	_, err := ReadReactions(strings.NewReader("1 C, 1 ORE => 1 A\n1 A => 1 B\n1 B => 1 C\n1 A => 1 FUEL"))
+	if err == nil || !strings.Contains(err.Error(), "line 2: reaction cycle: A (A -> C -> B -> A)") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
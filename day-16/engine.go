@@ -0,0 +1,108 @@
+package main
+
+import (
+	"sync"
+)
+
+// Engine runs phases splitting the output digits between worker goroutines.
+// The scratch buffers are kept between the phases and the calls, so an
+// engine must not be used by more than one goroutine at a time.
+type Engine struct {
+	workers int
+	prefix  []int
+	sums    []int
+}
+
+func NewEngine(workers int) *Engine {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Engine{
+		workers: workers,
+		sums:    make([]int, workers+1),
+	}
+}
+
+// Run runs count phases over n in place. Apart from n the only memory used
+// is the prefix sums buffer, which is kept for the next runs.
+func (e *Engine) Run(n *Number, count int) {
+	for i := 0; i < count; i++ {
+		if n.suffixOnly() {
+			e.suffixPhase(n)
+		} else {
+			e.blockPhase(n)
+		}
+	}
+}
+
+// parallel runs fn for every worker and waits for all of them.
+func (e *Engine) parallel(fn func(worker int)) {
+	if e.workers == 1 {
+		fn(0)
+		return
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < e.workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			fn(w)
+		}(w)
+	}
+	wg.Wait()
+}
+
+// chunk returns the part of [0, size) the worker is responsible for.
+func (e *Engine) chunk(worker, size int) (int, int) {
+	per := (size + e.workers - 1) / e.workers
+	from, to := worker*per, (worker+1)*per
+	if from > size {
+		from = size
+	}
+	if to > size {
+		to = size
+	}
+	return from, to
+}
+
+// blockPhase gives every worker every workers-th digit: the first digits
+// cover the most blocks, so contiguous chunks would not be balanced. Once the
+// prefix sums are there the digits are not read anymore and are overwritten.
+func (e *Engine) blockPhase(n *Number) {
+	if cap(e.prefix) < len(n.digits)+1 {
+		e.prefix = make([]int, len(n.digits)+1)
+	}
+	prefix := e.prefix[:len(n.digits)+1]
+	n.fillPrefix(prefix)
+	e.parallel(func(w int) {
+		for ix := w; ix < len(n.digits); ix += e.workers {
+			n.digits[ix] = n.blockDigit(prefix, ix)
+		}
+	})
+}
+
+// suffixPhase sums every worker's chunk first, then every worker runs the
+// suffix sum over its chunk starting with the total of the chunks after it.
+// Every digit is read right before it is overwritten.
+func (e *Engine) suffixPhase(n *Number) {
+	e.parallel(func(w int) {
+		from, to := e.chunk(w, len(n.digits))
+		total := 0
+		for _, d := range n.digits[from:to] {
+			total += int(d)
+		}
+		e.sums[w] = total
+	})
+	e.sums[e.workers] = 0
+	for w := e.workers - 1; w >= 0; w-- {
+		e.sums[w] += e.sums[w+1]
+	}
+	e.parallel(func(w int) {
+		from, to := e.chunk(w, len(n.digits))
+		total := e.sums[w+1]
+		for ix := to - 1; ix >= from; ix-- {
+			total += int(n.digits[ix])
+			n.digits[ix] = NewDigit(total)
+		}
+	})
+}
@@ -1,21 +1,16 @@
 package main
 
 import (
-	"bytes"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"runtime"
 	"strconv"
 	"strings"
 )
 
-var (
-	BASE_PATTERN = []int{0, 1, 0, -1}
-)
-
-type Digit uint8
-
 func abs(v int) int {
 	if v < 0 {
 		return -v
@@ -23,107 +18,223 @@ func abs(v int) int {
 	return v
 }
 
-func NewDigit(v int) Digit {
-	return Digit(abs(v) % 10)
+func NewDigit(v int) int8 {
+	return int8(abs(v) % 10)
 }
 
+// Number is a signal, possibly with its head cut off: digits[0] is the digit
+// at position off of the whole signal. The digits of a phase only depend on
+// the digits at the same or later positions, so the head is never needed to
+// compute the tail.
 type Number struct {
-	digits []Digit
+	digits []int8
+	off    int
 }
 
 func NewNumber(s string) *Number {
-	digits := make([]Digit, 0, len(s))
+	digits := make([]int8, 0, len(s))
 	for _, ch := range s {
 		if ch < '0' || ch > '9' {
 			panic(fmt.Sprintf("Unexpected digit: %c", ch))
 		}
-		digits = append(digits, Digit(ch-'0'))
+		digits = append(digits, int8(ch-'0'))
 	}
 	return &Number{
 		digits: digits,
 	}
 }
 
-func (n *Number) NextGenWithOff(off int) *Number {
-	digits := make([]Digit, 0, len(n.digits))
-	for gen := off; gen <= off+len(n.digits)-1; gen++ {
-		//next := genPattern(BASE_PATTERN, off, gen)
-		var d int
-		for _, digit := range n.digits {
-			//d += next() * int(digit)
-			d += int(digit)
+// NewRepeatedNumber creates the signal s repeated rep times with the digits
+// before off cut off. The repeated signal is never built: the kept digits are
+// read from s going round it.
+func NewRepeatedNumber(s string, rep, off int) (*Number, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("empty signal")
+	}
+	size := len(s) * rep
+	if off < 0 || off > size {
+		return nil, fmt.Errorf("offset %d is out of the signal of %d digits", off, size)
+	}
+	digits := make([]int8, size-off)
+	for ix := range digits {
+		ch := s[(off+ix)%len(s)]
+		if ch < '0' || ch > '9' {
+			return nil, fmt.Errorf("unexpected digit: %c", ch)
 		}
-		digits = append(digits, NewDigit(d))
+		digits[ix] = int8(ch - '0')
 	}
 	return &Number{
 		digits: digits,
+		off:    off,
+	}, nil
+}
+
+// Len is the length of the whole signal, the cut off head included.
+func (n *Number) Len() int {
+	return n.off + len(n.digits)
+}
+
+// Truncate returns the number with the digits before off cut off. The digits
+// are shared with n.
+func (n *Number) Truncate(off int) *Number {
+	if off < n.off || off > n.Len() {
+		panic(fmt.Sprintf("Offset %d is out of [%d, %d]", off, n.off, n.Len()))
+	}
+	return &Number{
+		digits: n.digits[off-n.off:],
+		off:    off,
+	}
+}
+
+func (n *Number) Clone() *Number {
+	return &Number{
+		digits: append([]int8{}, n.digits...),
+		off:    n.off,
 	}
 }
 
+// NextGenWithOff runs a phase and cuts off the digits before off.
+func (n *Number) NextGenWithOff(off int) *Number {
+	return n.Truncate(off).NextGen()
+}
+
+// NextGen runs a single phase and returns the result as a new number. When
+// every kept digit is in the second half of the signal, the pattern is all
+// ones from the digit on and a phase is a suffix sum. Otherwise the pattern
+// is split into the blocks of 1s and -1s, each summed up at once with prefix
+// sums, which is n/1 + n/2 + ... blocks, O(n log n) in total.
 func (n *Number) NextGen() *Number {
-	return n.NextGenWithOff(0)
+	res := n.Clone()
+	NewEngine(1).Run(res, 1)
+	return res
 }
 
-func (n *Number) String() string {
-	var buf bytes.Buffer
-	for _, digit := range n.digits {
+// suffixOnly tells if the pattern is 1 from every kept digit to the end:
+// output i sees 1s up to position 2i.
+func (n *Number) suffixOnly() bool {
+	return 2*n.off >= n.Len()-1
+}
+
+// fillPrefix puts the sum of the first i kept digits to prefix[i].
+func (n *Number) fillPrefix(prefix []int) {
+	prefix[0] = 0
+	for ix, d := range n.digits {
+		prefix[ix+1] = prefix[ix] + int(d)
+	}
+}
+
+// blockDigit computes the kept digit ix of the next phase. It only reads the
+// prefix sums, so the digits could be overwritten meanwhile.
+func (n *Number) blockDigit(prefix []int, ix int) int8 {
+	// sum returns the sum of the digits in [from, to) of the whole signal
+	sum := func(from, to int) int {
+		from, to = from-n.off, to-n.off
+		if to > len(n.digits) {
+			to = len(n.digits)
+		}
+		return prefix[to] - prefix[from]
+	}
+	end := n.Len()
+	pos := n.off + ix
+	// the pattern repeats every digit pos+1 times and the first pattern
+	// value is skipped, so the 1s start at pos, the -1s start 2 blocks later
+	width := pos + 1
+	total := 0
+	for start := pos; start < end; start += 4 * width {
+		total += sum(start, start+width)
+		if neg := start + 2*width; neg < end {
+			total -= sum(neg, neg+width)
+		}
+	}
+	return NewDigit(total)
+}
+
+// Phases runs count phases keeping the digits from off on. n stays
+// untouched.
+func (n *Number) Phases(count, off int) *Number {
+	res := n.Truncate(off).Clone()
+	NewEngine(1).Run(res, count)
+	return res
+}
+
+// Window returns size digits starting at the position off of the whole
+// signal. The digits must not be cut off.
+func (n *Number) Window(off, size int) (string, error) {
+	from, to := off-n.off, off-n.off+size
+	if size < 0 || from < 0 || to > len(n.digits) {
+		return "", fmt.Errorf("window [%d, %d) is out of the digits kept [%d, %d)", off, off+size, n.off, n.Len())
+	}
+	return digitsString(n.digits[from:to]), nil
+}
+
+func digitsString(digits []int8) string {
+	var buf strings.Builder
+	buf.Grow(len(digits))
+	for _, digit := range digits {
 		buf.WriteByte(byte('0' + digit))
 	}
 	return buf.String()
 }
 
+func (n *Number) String() string {
+	return digitsString(n.digits)
+}
+
 func noerr(err error) {
 	if err != nil {
 		log.Fatalf("Unexpected error: %s", err)
 	}
 }
 
-func genPattern(base []int, off int, gen int) func() int {
-	ix := (off / (gen + 1)) % len(base)
-	n := off % (gen + 1)
-	n += 1
-	return func() int {
-		if n > gen {
-			n = 0
-			ix++
-		}
-		if ix >= len(base) {
-			ix = 0
-		}
-		n++
-		return base[ix]
-	}
-}
-
 const (
-	REP = 10_000
-	OFF = 7
-	GEN = 100
+	// the length of the message and of the message offset
+	MSG_LEN = 8
+	OFF_LEN = 7
 )
 
-func main() {
-	f, err := os.Open("INPUT-TST6")
+func readSignal(path string) string {
+	f, err := os.Open(path)
 	noerr(err)
 	defer f.Close()
 	bs, err := ioutil.ReadAll(f)
 	noerr(err)
-	s := strings.Trim(string(bs), "\n\r\t")
+	return strings.Trim(string(bs), "\n\r\t")
+}
 
-	var buf bytes.Buffer
-	for i := 0; i < REP; i++ {
-		buf.WriteString(s)
+// decode repeats the signal and returns the message: MSG_LEN digits after
+// the phases at the offset found in the first OFF_LEN digits.
+func decode(s string, rep, phases int, engine *Engine) (string, error) {
+	if len(s)*rep < OFF_LEN {
+		return "", fmt.Errorf("signal of %d digits has no offset", len(s)*rep)
 	}
-	s = buf.String()
-
-	off, err := strconv.Atoi(s[:OFF])
-	noerr(err)
+	off, err := strconv.Atoi(strings.Repeat(s, OFF_LEN/len(s)+1)[:OFF_LEN])
+	if err != nil {
+		return "", err
+	}
+	num, err := NewRepeatedNumber(s, rep, off)
+	if err != nil {
+		return "", err
+	}
+	engine.Run(num, phases)
+	return num.Window(off, MSG_LEN)
+}
 
-	num := NewNumber(s)
+func main() {
+	input := flag.String("input", "INPUT", "signal file")
+	phases := flag.Int("phases", 100, "number of phases")
+	rep := flag.Int("repeat", 10_000, "times the signal is repeated for the message")
+	workers := flag.Int("workers", runtime.GOMAXPROCS(0), "number of goroutines computing a phase")
+	flag.Parse()
 
-	for i := 0; i < GEN; i++ {
-		log.Printf("Generation: %d", i)
-		num = num.NextGen()
-	}
+	s := readSignal(*input)
+	engine := NewEngine(*workers)
 
-	log.Printf("Result: %s", num.String()[off:off+8])
+	num := NewNumber(s)
+	engine.Run(num, *phases)
+	head, err := num.Window(0, MSG_LEN)
+	noerr(err)
+	log.Printf("First %d digits: %s", MSG_LEN, head)
+	msg, err := decode(s, *rep, *phases, engine)
+	noerr(err)
+	log.Printf("Result: %s", msg)
 }
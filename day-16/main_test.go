@@ -0,0 +1,234 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+var (
+	BASE_PATTERN = []int{0, 1, 0, -1}
+)
+
+func genPattern(base []int, off int, gen int) func() int {
+	ix := (off / (gen + 1)) % len(base)
+	n := off % (gen + 1)
+	n += 1
+	return func() int {
+		if n > gen {
+			n = 0
+			ix++
+		}
+		if ix >= len(base) {
+			ix = 0
+		}
+		n++
+		return base[ix]
+	}
+}
+
+// naiveGen is the O(n^2) phase straight from the puzzle text.
+func naiveGen(n *Number) *Number {
+	digits := make([]int8, 0, len(n.digits))
+	for gen := n.off; gen < n.Len(); gen++ {
+		next := genPattern(BASE_PATTERN, n.off, gen)
+		var d int
+		for _, digit := range n.digits {
+			d += next() * int(digit)
+		}
+		digits = append(digits, NewDigit(d))
+	}
+	return &Number{
+		digits: digits,
+		off:    n.off,
+	}
+}
+
+func TestNumber_NextGen(t *testing.T) {
+	num := NewNumber(readSignal("INPUT-TST"))
+	for _, want := range []string{"48226158", "34040438", "03415518", "01029498"} {
+		num = num.NextGen()
+		if got := num.String(); got != want {
+			t.Errorf("NextGen() = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestNumber_Phases(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"INPUT-TST2", "24176176"},
+		{"INPUT-TST3", "73745418"},
+		{"INPUT-TST4", "52432133"},
+	}
+	for _, tt := range tests {
+		num := NewNumber(readSignal(tt.input))
+		if got, _ := num.Phases(100, 0).Window(0, 8); got != tt.want {
+			t.Errorf("%s: Phases() = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"INPUT-TST6", "84462026"},
+		{"INPUT-TST7", "78725270"},
+		{"INPUT-TST8", "53553731"},
+	}
+	for _, tt := range tests {
+		for _, workers := range []int{1, 3, 8} {
+			got, err := decode(readSignal(tt.input), 10_000, 100, NewEngine(workers))
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %s", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("%s, %d workers: decode() = %s, want %s", tt.input, workers, got, tt.want)
+			}
+		}
+	}
+}
+
+// Both phase algorithms must match the naive one on every input and every
+// offset, the suffix sums are only picked in the second half.
+func TestNumber_NextGen_Naive(t *testing.T) {
+	for _, input := range []string{"INPUT-TST", "INPUT-TST2", "INPUT-TST3", "INPUT-TST4", "INPUT-TST5", "INPUT-TST6", "INPUT-TST7", "INPUT-TST8"} {
+		s := readSignal(input)
+		s = strings.Repeat(s, 1+40/len(s))
+		for off := 0; off < len(s); off++ {
+			num := NewNumber(s).Truncate(off)
+			want := num
+			for i := 0; i < 3; i++ {
+				want = naiveGen(want)
+			}
+			got := num.Phases(3, off).String()
+			if got != want.String() {
+				t.Fatalf("%s at %d: Phases() = %s, want %s", input, off, got, want)
+			}
+			if num.suffixOnly() {
+				blocks := num.Clone()
+				NewEngine(1).blockPhase(blocks)
+				if got, want := blocks.String(), naiveGen(num).String(); got != want {
+					t.Fatalf("%s at %d: blockPhase() = %s, want %s", input, off, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	for _, s := range []string{"123", "99999991234", "12a45678901"} {
+		if _, err := decode(s, 1, 1, NewEngine(1)); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
+
+// The engine must give the same digits whatever the number of workers, even
+// with more workers than digits.
+func TestEngine_Run(t *testing.T) {
+	for _, input := range []string{"INPUT-TST", "INPUT-TST5", "INPUT"} {
+		num := NewNumber(readSignal(input))
+		for _, off := range []int{0, 1, num.Len() / 3, num.Len() / 2, num.Len() - 1} {
+			want := num.Truncate(off)
+			for i := 0; i < 5; i++ {
+				want = want.NextGen()
+			}
+			for _, workers := range []int{1, 2, 7, 1000} {
+				got := num.Truncate(off).Clone()
+				NewEngine(workers).Run(got, 5)
+				if got.String() != want.String() {
+					t.Errorf("%s at %d, %d workers: Run() = %s, want %s", input, off, workers, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestNewRepeatedNumber(t *testing.T) {
+	num, err := NewRepeatedNumber("12345", 3, 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := num.String(); got != "34512345" || num.Len() != 15 {
+		t.Errorf("NewRepeatedNumber() = %s of %d, want 34512345 of 15", got, num.Len())
+	}
+	for _, tt := range []struct {
+		s        string
+		rep, off int
+	}{
+		{"", 1, 0},
+		{"123", 2, 7},
+		{"123", 2, -1},
+		{"1a3", 2, 0},
+	} {
+		if _, err := NewRepeatedNumber(tt.s, tt.rep, tt.off); err == nil {
+			t.Errorf("expected an error for %+v", tt)
+		}
+	}
+}
+
+func TestNumber_Window(t *testing.T) {
+	num := NewNumber("0123456789").Truncate(3)
+	tests := []struct {
+		off, size int
+		want      string
+		ok        bool
+	}{
+		{3, 3, "345", true},
+		{7, 3, "789", true},
+		{10, 0, "", true},
+		{2, 3, "", false},
+		{8, 3, "", false},
+		{4, -1, "", false},
+	}
+	for _, tt := range tests {
+		got, err := num.Window(tt.off, tt.size)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("Window(%d, %d) = %q, %v, want %q", tt.off, tt.size, got, err, tt.want)
+		}
+	}
+}
+
+// Once the prefix sums buffer is there, Run must not allocate anything but
+// the worker closure per phase, whatever the size of the signal.
+func TestEngine_Run_Allocs(t *testing.T) {
+	num, err := NewRepeatedNumber(readSignal("INPUT"), 10, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	engine := NewEngine(1)
+	engine.Run(num, 1)
+	if allocs := testing.AllocsPerRun(5, func() { engine.Run(num, 3) }); allocs > 3 {
+		t.Errorf("Run() makes %.1f allocations for 3 phases", allocs)
+	}
+}
+
+func benchmarkPhase(b *testing.B, rep, off, workers int) {
+	num, err := NewRepeatedNumber(readSignal("INPUT"), rep, off)
+	if err != nil {
+		b.Fatalf("unexpected error: %s", err)
+	}
+	engine := NewEngine(workers)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine.Run(num, 1)
+	}
+}
+
+func BenchmarkPhase_Blocks_1(b *testing.B)  { benchmarkPhase(b, 100, 0, 1) }
+func BenchmarkPhase_Blocks_4(b *testing.B)  { benchmarkPhase(b, 100, 0, 4) }
+func BenchmarkPhase_Suffix_1(b *testing.B)  { benchmarkPhase(b, 10_000, 5_971_269, 1) }
+func BenchmarkPhase_Suffix_4(b *testing.B)  { benchmarkPhase(b, 10_000, 5_971_269, 4) }
+func BenchmarkPhase_Suffix_16(b *testing.B) { benchmarkPhase(b, 10_000, 5_971_269, 16) }
+
+func BenchmarkDecode(b *testing.B) {
+	s := readSignal("INPUT")
+	engine := NewEngine(4)
+	for i := 0; i < b.N; i++ {
+		decode(s, 10_000, 100, engine)
+	}
+}
@@ -0,0 +1,7 @@
+module sandbox/advent-of-code-2019/day-17
+
+go 1.18
+
+require sandbox/advent-of-code-2019/lib v0.0.0
+
+replace sandbox/advent-of-code-2019/lib => ../lib
@@ -184,11 +184,11 @@ func Compute(program *Program, input <-chan int64, output chan<- int64) {
 			_, mode1, mode2 := program.OpDuo(pcnt)
 			printf("duo mode: %d, %d", mode1, mode2)
 			cond, dest := program.ReadVal(rel, pcnt+1, mode1), program.ReadVal(rel, pcnt+2, mode2)
-			if cond > 0 {
-				printf("cond %d > 0, jump to %d", cond, dest)
+			if cond != 0 {
+				printf("cond %d != 0, jump to %d", cond, dest)
 				pcnt = dest
 			} else {
-				printf("cond %d is not > 0, continue", cond)
+				printf("cond %d is 0, continue", cond)
 				pcnt += 3
 			}
 		case CODE_JMPZ:
@@ -8,6 +8,8 @@ import (
 	"os"
 	"strconv"
 	"strings"
+
+	"sandbox/advent-of-code-2019/lib/grid"
 )
 
 const (
@@ -33,28 +35,17 @@ var (
 	PATH_C = []string{"R", "10", "L", "12", "R", "10"}
 )
 
-type Coord struct {
-	x, y int
-}
-
-func parseField(input []byte) [][]byte {
-	return bytes.Split(bytes.Trim(input, "\n\r\t"), []byte{10})
+func parseField(input []byte) (*grid.Grid, error) {
+	return grid.Parse(bytes.Trim(input, "\n\r\t"))
 }
 
 var (
-	STEP_LEFT  = Coord{-1, 0}
-	STEP_RIGHT = Coord{1, 0}
-	STEP_UP    = Coord{0, -1}
-	STEP_DOWN  = Coord{0, 1}
-
-	STEPS = []Coord{
-		STEP_LEFT,
-		STEP_RIGHT,
-		STEP_DOWN,
-		STEP_UP,
-	}
+	STEP_LEFT  = grid.Left
+	STEP_RIGHT = grid.Right
+	STEP_UP    = grid.Up
+	STEP_DOWN  = grid.Down
 
-	STEP_DIR = map[Coord]byte{
+	STEP_DIR = map[grid.Point]byte{
 		STEP_LEFT:  LEFT,
 		STEP_RIGHT: RIGHT,
 		STEP_UP:    UP,
@@ -104,45 +95,37 @@ func getMainRoutine(path string) string {
 	return strings.Join(chunks, ",")
 }
 
-func findIntersects(field [][]byte) []Coord {
-	res := make([]Coord, 0, 1)
-	for i := 1; i < len(field)-1; i++ {
-	Next:
-		for j := 1; j < len(field[0])-1; j++ {
-			if field[i][j] != SCAFF {
+func findIntersects(field *grid.Grid) []grid.Point {
+	res := make([]grid.Point, 0, 1)
+Next:
+	for _, pos := range field.Find(SCAFF) {
+		ns := field.Neighbors4(pos)
+		if len(ns) < len(grid.Dirs4) {
+			continue
+		}
+		for _, npos := range ns {
+			if ch, _ := field.At(npos); ch != SCAFF {
 				continue Next
 			}
-			for _, s := range STEPS {
-				if field[i+s.x][j+s.y] != SCAFF {
-					continue Next
-				}
-			}
-			res = append(res, Coord{i, j})
 		}
+		res = append(res, pos)
 	}
 	return res
 }
 
-func withinRange(field [][]byte, pos Coord) bool {
-	return pos.x >= 0 && pos.y >= 0 && pos.x < len(field[0]) && pos.y < len(field)
-}
-
-func traverseField(field [][]byte) []byte {
+func traverseField(field *grid.Grid) []byte {
 	start, dir := findRobot(field)
-	visited := make(map[Coord]struct{})
+	visited := make(map[grid.Point]struct{})
 	path := make([]byte, 0, 1)
 
-	var visit func(Coord)
-	visit = func(pos Coord) {
+	var visit func(grid.Point)
+	visit = func(pos grid.Point) {
 		visited[pos] = struct{}{}
 		var turn byte
-		var nextStep Coord
-		for _, step := range STEPS {
-			npos := Coord{pos.x + step.x, pos.y + step.y}
-			if !withinRange(field, npos) {
-				continue
-			}
-			if field[npos.y][npos.x] != SCAFF {
+		var nextStep grid.Point
+		for _, step := range grid.Dirs4 {
+			npos := pos.Add(step)
+			if ch, ok := field.At(npos); !ok || ch != SCAFF {
 				continue
 			}
 			if _, ok := visited[npos]; ok {
@@ -161,8 +144,8 @@ func traverseField(field [][]byte) []byte {
 		}
 		pathlen := 0
 		for {
-			newpos := Coord{pos.x + nextStep.x, pos.y + nextStep.y}
-			if !withinRange(field, newpos) || field[newpos.y][newpos.x] != SCAFF {
+			newpos := pos.Add(nextStep)
+			if ch, ok := field.At(newpos); !ok || ch != SCAFF {
 				break
 			}
 			visited[newpos] = struct{}{}
@@ -184,7 +167,7 @@ func traverseField(field [][]byte) []byte {
 	return path
 }
 
-func getTurn(dir Coord, cur byte) byte {
+func getTurn(dir grid.Point, cur byte) byte {
 	switch cur {
 	case UP:
 		switch dir {
@@ -218,16 +201,13 @@ func getTurn(dir Coord, cur byte) byte {
 	return 0
 }
 
-func findRobot(field [][]byte) (Coord, byte) {
-	for i := 0; i < len(field); i++ {
-		for j := 0; j < len(field[0]); j++ {
-			switch dir := field[i][j]; dir {
-			case UP, DOWN, LEFT, RIGHT:
-				return Coord{j, i}, dir
-			}
+func findRobot(field *grid.Grid) (grid.Point, byte) {
+	for _, dir := range []byte{UP, DOWN, LEFT, RIGHT} {
+		if pos := field.Find(dir); len(pos) > 0 {
+			return pos[0], dir
 		}
 	}
-	return Coord{-1, -1}, 0
+	return grid.Point{X: -1, Y: -1}, 0
 }
 
 func main() {
@@ -258,7 +238,8 @@ func main() {
 
 	fmt.Print(string(buf.Bytes()))
 
-	field := parseField(buf.Bytes())
+	field, err := parseField(buf.Bytes())
+	noerr(err)
 	//intersects := findIntersects(field)
 
 	//res := 0
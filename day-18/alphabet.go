@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Alphabet maps vault tiles to their meaning. Every key has an ID, the door
+// with the same ID is opened by that key. Any tile which is not a wall is
+// walkable.
+type Alphabet struct {
+	Wall     byte
+	Floor    byte
+	Entrance byte
+	keys     map[byte]int
+	doors    map[byte]int
+	// the tiles by ID
+	keyTiles, doorTiles string
+}
+
+// NewAlphabet creates an alphabet where keys[i] opens doors[i].
+func NewAlphabet(wall, floor, entrance byte, keys, doors string) (*Alphabet, error) {
+	if len(keys) != len(doors) {
+		return nil, fmt.Errorf("got %d keys for %d doors", len(keys), len(doors))
+	}
+	a := &Alphabet{
+		Wall:      wall,
+		Floor:     floor,
+		Entrance:  entrance,
+		keys:      make(map[byte]int),
+		doors:     make(map[byte]int),
+		keyTiles:  keys,
+		doorTiles: doors,
+	}
+	seen := map[byte]bool{wall: true}
+	for _, ch := range []byte{floor, entrance} {
+		if seen[ch] {
+			return nil, fmt.Errorf("tile %q is used twice", ch)
+		}
+		seen[ch] = true
+	}
+	for ix := 0; ix < len(keys); ix++ {
+		for _, ch := range []byte{keys[ix], doors[ix]} {
+			if seen[ch] {
+				return nil, fmt.Errorf("tile %q is used twice", ch)
+			}
+			seen[ch] = true
+		}
+		a.keys[keys[ix]] = ix
+		a.doors[doors[ix]] = ix
+	}
+	return a, nil
+}
+
+// DefaultAlphabet is the puzzle notation: walls are '#', the entrance is '@',
+// keys are a-z and doors are A-Z.
+func DefaultAlphabet() *Alphabet {
+	a, err := NewAlphabet('#', '.', '@', "abcdefghijklmnopqrstuvwxyz", "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	noerr(err)
+	return a
+}
+
+// Size returns the number of key and door pairs.
+func (a *Alphabet) Size() int {
+	return len(a.keyTiles)
+}
+
+// Key returns the tile of the key with the given ID, id must be below Size().
+func (a *Alphabet) Key(id int) byte {
+	return a.keyTiles[id]
+}
+
+// Door returns the tile of the door with the given ID, id must be below
+// Size().
+func (a *Alphabet) Door(id int) byte {
+	return a.doorTiles[id]
+}
+
+func (a *Alphabet) IsWall(ch byte) bool {
+	return ch == a.Wall
+}
+
+func (a *Alphabet) IsFloor(ch byte) bool {
+	return ch == a.Floor
+}
+
+func (a *Alphabet) IsEntrance(ch byte) bool {
+	return ch == a.Entrance
+}
+
+func (a *Alphabet) KeyID(ch byte) (int, bool) {
+	id, ok := a.keys[ch]
+	return id, ok
+}
+
+func (a *Alphabet) DoorID(ch byte) (int, bool) {
+	id, ok := a.doors[ch]
+	return id, ok
+}
+
+func (a *Alphabet) IsKey(ch byte) bool {
+	_, ok := a.keys[ch]
+	return ok
+}
+
+func (a *Alphabet) IsDoor(ch byte) bool {
+	_, ok := a.doors[ch]
+	return ok
+}
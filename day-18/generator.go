@@ -0,0 +1,257 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+
+	"sandbox/advent-of-code-2019/lib/grid"
+)
+
+const (
+	GEN_MAX_ATTEMPTS = 1000
+)
+
+var (
+	ErrGeneratorGaveUp = errors.New("could not generate a vault matching the config")
+)
+
+type GeneratorConfig struct {
+	// the size inside the outer wall, both are rounded up to the next odd
+	// number as the maze walls take the even rows and columns
+	Width, Height int
+	Keys          int
+	// the longest chain of keys where every next one is locked behind the
+	// door of the previous one
+	Depth     int
+	Entrances int
+	Seed      int64
+	// the tiles of the vault, DefaultAlphabet if nil. The number of keys is
+	// limited by the size of the alphabet.
+	Alphabet *Alphabet
+}
+
+func (c GeneratorConfig) validate() error {
+	if c.Width < 3 || c.Height < 3 {
+		return fmt.Errorf("vault %dx%d is too small", c.Width, c.Height)
+	}
+	if c.Keys < 1 {
+		return fmt.Errorf("invalid number of keys: %d", c.Keys)
+	}
+	if c.Keys > c.Alphabet.Size() {
+		return fmt.Errorf("%d keys do not fit the alphabet of %d keys", c.Keys, c.Alphabet.Size())
+	}
+	if c.Entrances < 1 {
+		return fmt.Errorf("invalid number of entrances: %d", c.Entrances)
+	}
+	if c.Depth < 0 || c.Depth >= c.Keys {
+		return fmt.Errorf("door depth %d is out of range for %d keys", c.Depth, c.Keys)
+	}
+	cells := ((c.Width + 1) / 2) * ((c.Height + 1) / 2)
+	if c.Keys+c.Entrances+c.Depth > cells {
+		return fmt.Errorf("vault %dx%d is too small for %d keys, %d doors and %d entrances",
+			c.Width, c.Height, c.Keys, c.Depth, c.Entrances)
+	}
+	return nil
+}
+
+// GenerateVault produces a random solvable vault in the puzzle notation. The
+// vault is a perfect maze, so there is exactly one walk between any 2 cells.
+// The same config always produces the same vault.
+func GenerateVault(config GeneratorConfig) (*grid.Grid, error) {
+	if config.Alphabet == nil {
+		config.Alphabet = DefaultAlphabet()
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	if config.Width%2 == 0 {
+		config.Width++
+	}
+	if config.Height%2 == 0 {
+		config.Height++
+	}
+	rnd := rand.New(rand.NewSource(config.Seed))
+	for attempt := 0; attempt < GEN_MAX_ATTEMPTS; attempt++ {
+		g := carveMaze(rnd, config.Alphabet, config.Width, config.Height)
+		if !placeItems(rnd, g, config) {
+			continue
+		}
+		if depth, ok := doorDepth(g, config.Alphabet); ok && depth == config.Depth {
+			return g, nil
+		}
+	}
+	return nil, ErrGeneratorGaveUp
+}
+
+func WriteVault(w io.Writer, g *grid.Grid) error {
+	for _, row := range g.Rows() {
+		if _, err := fmt.Fprintf(w, "%s\n", row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// carveMaze runs a randomized DFS over the odd cells knocking down the walls
+// in between.
+func carveMaze(rnd *rand.Rand, alphabet *Alphabet, width, height int) *grid.Grid {
+	g := grid.New(width+2, height+2, alphabet.Wall)
+	// the maze cells are at odd coordinates of the outer grid
+	start := grid.Point{X: 1, Y: 1}
+	g.Set(start, alphabet.Floor)
+	stack := []grid.Point{start}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		nexts := make([]grid.Point, 0, len(grid.Dirs4))
+		for _, s := range grid.Dirs4 {
+			np := cur.Add(s).Add(s)
+			if np.X < 1 || np.Y < 1 || np.X > width || np.Y > height {
+				continue
+			}
+			if ch, _ := g.At(np); alphabet.IsWall(ch) {
+				nexts = append(nexts, np)
+			}
+		}
+		if len(nexts) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		np := nexts[rnd.Intn(len(nexts))]
+		g.Set(grid.Point{X: (cur.X + np.X) / 2, Y: (cur.Y + np.Y) / 2}, alphabet.Floor)
+		g.Set(np, alphabet.Floor)
+		stack = append(stack, np)
+	}
+	return g
+}
+
+// placeItems drops the entrances and the keys into random cells, then locks a
+// chain of config.Depth keys: the door opened by key i is put on the walk from
+// the first entrance to key i+1, below the fork leading to key i.
+func placeItems(rnd *rand.Rand, g *grid.Grid, config GeneratorConfig) bool {
+	alphabet := config.Alphabet
+	cells := make([]grid.Point, 0, 1)
+	for y := 1; y < g.Height()-1; y += 2 {
+		for x := 1; x < g.Width()-1; x += 2 {
+			cells = append(cells, grid.Point{X: x, Y: y})
+		}
+	}
+	rnd.Shuffle(len(cells), func(i, j int) {
+		cells[i], cells[j] = cells[j], cells[i]
+	})
+	entrances, cells := cells[:config.Entrances], cells[config.Entrances:]
+	keys := cells[:config.Keys]
+	for _, pos := range entrances {
+		g.Set(pos, alphabet.Entrance)
+	}
+	for ix, pos := range keys {
+		g.Set(pos, alphabet.Key(ix))
+	}
+
+	parents := walkTree(g, alphabet, entrances[0])
+	for ix := 0; ix < config.Depth; ix++ {
+		locked, opener := keys[ix+1], keys[ix]
+		forks := make(map[grid.Point]bool)
+		for ptr := opener; ; ptr = parents[ptr] {
+			forks[ptr] = true
+			if ptr == entrances[0] {
+				break
+			}
+		}
+		candidates := make([]grid.Point, 0, 1)
+		for ptr := locked; !forks[ptr]; ptr = parents[ptr] {
+			if ch, _ := g.At(ptr); alphabet.IsFloor(ch) {
+				candidates = append(candidates, ptr)
+			}
+		}
+		if len(candidates) == 0 {
+			return false
+		}
+		door := candidates[rnd.Intn(len(candidates))]
+		g.Set(door, alphabet.Door(ix))
+	}
+	return true
+}
+
+// walkTree runs a BFS from the given cell and returns the parent of every
+// walkable cell.
+func walkTree(g *grid.Grid, alphabet *Alphabet, from grid.Point) map[grid.Point]grid.Point {
+	parents := map[grid.Point]grid.Point{from: from}
+	q := []grid.Point{from}
+	var cur grid.Point
+	for len(q) > 0 {
+		cur, q = q[0], q[1:]
+		for _, np := range g.Neighbors4(cur) {
+			if _, ok := parents[np]; ok {
+				continue
+			}
+			if ch, _ := g.At(np); alphabet.IsWall(ch) {
+				continue
+			}
+			parents[np] = cur
+			q = append(q, np)
+		}
+	}
+	return parents
+}
+
+// doorDepth collects the keys in rounds: every round takes all the keys
+// reachable with the ones collected before. Returns the number of rounds
+// beyond the first one and false if some keys can never be reached.
+func doorDepth(g *grid.Grid, alphabet *Alphabet) (int, bool) {
+	var all, keys KeySet
+	starts := make([]grid.Point, 0, 1)
+	for y, row := range g.Rows() {
+		for x, ch := range row {
+			if id, ok := alphabet.KeyID(ch); ok {
+				all = all.With(id)
+			}
+			if alphabet.IsEntrance(ch) {
+				starts = append(starts, grid.Point{X: x, Y: y})
+			}
+		}
+	}
+	for rounds := 0; ; rounds++ {
+		next := keys
+		for _, start := range starts {
+			next = next.Union(reachableKeys(g, alphabet, start, keys))
+		}
+		if next.Contains(all) {
+			return rounds, true
+		}
+		if next.Len() == keys.Len() {
+			return rounds, false
+		}
+		keys = next
+	}
+}
+
+func reachableKeys(g *grid.Grid, alphabet *Alphabet, from grid.Point, keys KeySet) KeySet {
+	var res KeySet
+	visited := map[grid.Point]bool{from: true}
+	q := []grid.Point{from}
+	var cur grid.Point
+	for len(q) > 0 {
+		cur, q = q[0], q[1:]
+		ch, _ := g.At(cur)
+		if id, ok := alphabet.KeyID(ch); ok {
+			res = res.With(id)
+		}
+		for _, np := range g.Neighbors4(cur) {
+			if visited[np] {
+				continue
+			}
+			nch, _ := g.At(np)
+			if alphabet.IsWall(nch) {
+				continue
+			}
+			if id, ok := alphabet.DoorID(nch); ok && !keys.Has(id) {
+				continue
+			}
+			visited[np] = true
+			q = append(q, np)
+		}
+	}
+	return res
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"sandbox/advent-of-code-2019/lib/grid"
+)
+
+func generate(t testing.TB, config GeneratorConfig) *grid.Grid {
+	g, err := GenerateVault(config)
+	if err != nil {
+		t.Fatalf("failed to generate a vault for %+v: %s", config, err)
+	}
+	return g
+}
+
+func solveBFS(g *grid.Grid) int {
+	var buf bytes.Buffer
+	WriteVault(&buf, g)
+	open, doors, keys, start := getGrid(&buf)
+	return solve(open, doors, keys, start)
+}
+
+func solveTransits(t testing.TB, g *grid.Grid) int {
+	vault, err := NewVault(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	res, err := vault.Solve()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return res
+}
+
+func TestGenerateVault(t *testing.T) {
+	config := GeneratorConfig{Width: 21, Height: 15, Keys: 8, Depth: 4, Entrances: 2, Seed: 5}
+	g := generate(t, config)
+	if h, w := g.Height(), g.Width(); h != 17 || w != 23 {
+		t.Errorf("vault size is %dx%d, want 23x17", w, h)
+	}
+	alphabet := DefaultAlphabet()
+	keys, doors, entrances := 0, 0, 0
+	for _, row := range g.Rows() {
+		for _, ch := range row {
+			switch {
+			case alphabet.IsKey(ch):
+				keys++
+			case alphabet.IsDoor(ch):
+				doors++
+			case alphabet.IsEntrance(ch):
+				entrances++
+			}
+		}
+	}
+	if keys != 8 || doors != 4 || entrances != 2 {
+		t.Errorf("got %d keys, %d doors, %d entrances, want 8, 4, 2", keys, doors, entrances)
+	}
+	if depth, ok := doorDepth(g, alphabet); !ok || depth != 4 {
+		t.Errorf("doorDepth() = %d, %t, want 4, true", depth, ok)
+	}
+
+	again := generate(t, config)
+	if g.String() != again.String() {
+		t.Errorf("the same seed must produce the same vault")
+	}
+}
+
+func TestGenerateVault_CustomAlphabet(t *testing.T) {
+	keys := "abcdefghijklmnopqrstuvwxyz0123456789"
+	doors := "ABCDEFGHIJKLMNOPQRSTUVWXYZ()*+,-/:;<"
+	alphabet, err := NewAlphabet('#', ' ', '@', keys, doors)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	config := GeneratorConfig{Width: 21, Height: 21, Keys: 30, Depth: 3, Entrances: 1, Seed: 2, Alphabet: alphabet}
+	g := generate(t, config)
+	found := 0
+	for _, row := range g.Rows() {
+		for _, ch := range row {
+			if alphabet.IsKey(ch) {
+				found++
+			}
+			if ch == '.' {
+				t.Fatalf("the default floor tile in a custom alphabet vault:\n%s", g)
+			}
+		}
+	}
+	if found != 30 {
+		t.Errorf("got %d keys, want 30", found)
+	}
+	if depth, ok := doorDepth(g, alphabet); !ok || depth != 3 {
+		t.Errorf("doorDepth() = %d, %t, want 3, true", depth, ok)
+	}
+	// solving 30 keys takes too long, but the solver has to see them all
+	vault, err := NewVault(g, WithAlphabet(alphabet))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := vault.allKeys.Len(); got != 30 {
+		t.Errorf("allKeys.Len() = %d, want 30", got)
+	}
+
+	config.Keys = len(keys) + 1
+	if _, err := GenerateVault(config); err == nil {
+		t.Errorf("more keys than the alphabet has: want an error")
+	}
+}
+
+func TestGenerateVault_Errors(t *testing.T) {
+	configs := []GeneratorConfig{
+		{Width: 1, Height: 9, Keys: 1, Entrances: 1},
+		{Width: 9, Height: 9, Keys: 0, Entrances: 1},
+		{Width: 9, Height: 9, Keys: 27, Entrances: 1},
+		{Width: 9, Height: 9, Keys: 3, Depth: 3, Entrances: 1},
+		{Width: 9, Height: 9, Keys: 3, Entrances: 0},
+		{Width: 3, Height: 3, Keys: 3, Depth: 1, Entrances: 1},
+	}
+	for _, config := range configs {
+		if _, err := GenerateVault(config); err == nil {
+			t.Errorf("expected an error for %+v", config)
+		}
+	}
+}
+
+// The plain BFS over (position, keys) in main.go is slow but simple, so it
+// serves as the reference for the transit graph solver.
+func TestGenerateVault_CrossCheck(t *testing.T) {
+	for seed := int64(0); seed < 30; seed++ {
+		config := GeneratorConfig{
+			Width:     11 + int(seed%3)*4,
+			Height:    9 + int(seed%4)*2,
+			Keys:      3 + int(seed%5),
+			Depth:     int(seed % 3),
+			Entrances: 1,
+			Seed:      seed,
+		}
+		g := generate(t, config)
+		want, got := solveBFS(g), solveTransits(t, g)
+		if got != want {
+			t.Errorf("seed %d: transit solver = %d, BFS = %d\n%s", seed, got, want, g)
+		}
+	}
+}
+
+var benchConfig = GeneratorConfig{Width: 31, Height: 31, Keys: 10, Depth: 4, Entrances: 1, Seed: 42}
+
+func BenchmarkSolve_BFS(b *testing.B) {
+	g := generate(b, benchConfig)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		solveBFS(g)
+	}
+}
+
+func BenchmarkSolve_Transits(b *testing.B) {
+	g := generate(b, benchConfig)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		solveTransits(b, g)
+	}
+}
+
+func BenchmarkGenerateVault(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		config := benchConfig
+		config.Seed = int64(i)
+		generate(b, config)
+	}
+}
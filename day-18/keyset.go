@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"strings"
+)
+
+// KeySet is a set of key IDs backed by a bitset which grows as needed, so the
+// number of keys is not limited by the width of a machine word. The zero value
+// is an empty set. Sets are never modified in place: With returns a new one,
+// which makes them safe to share between search states.
+type KeySet []uint64
+
+func (s KeySet) Has(id int) bool {
+	word := id / 64
+	if word >= len(s) {
+		return false
+	}
+	return s[word]&(1<<uint(id%64)) > 0
+}
+
+func (s KeySet) With(id int) KeySet {
+	word := id / 64
+	size := len(s)
+	if word >= size {
+		size = word + 1
+	}
+	res := make(KeySet, size)
+	copy(res, s)
+	res[word] |= 1 << uint(id%64)
+	return res
+}
+
+func (s KeySet) Union(o KeySet) KeySet {
+	if len(o) > len(s) {
+		s, o = o, s
+	}
+	res := make(KeySet, len(s))
+	copy(res, s)
+	for ix, w := range o {
+		res[ix] |= w
+	}
+	return res
+}
+
+// Contains tells whether every key of o is in s as well.
+func (s KeySet) Contains(o KeySet) bool {
+	for ix, w := range o {
+		var have uint64
+		if ix < len(s) {
+			have = s[ix]
+		}
+		if have&w != w {
+			return false
+		}
+	}
+	return true
+}
+
+func (s KeySet) Len() int {
+	cnt := 0
+	for _, w := range s {
+		cnt += bits.OnesCount64(w)
+	}
+	return cnt
+}
+
+// Key returns a compact representation of the set suitable for map keys.
+// Equal sets produce equal keys.
+func (s KeySet) Key() string {
+	size := len(s)
+	for size > 0 && s[size-1] == 0 {
+		size--
+	}
+	var buf strings.Builder
+	var word [8]byte
+	for _, w := range s[:size] {
+		binary.LittleEndian.PutUint64(word[:], w)
+		buf.Write(word[:])
+	}
+	return buf.String()
+}
@@ -1,15 +1,14 @@
 package main
 
 import (
-	"bufio"
+	"flag"
+	"fmt"
 	"io"
 	"log"
 	"os"
-)
 
-type v2 struct {
-	x, y int
-}
+	"sandbox/advent-of-code-2019/lib/grid"
+)
 
 type v3 struct {
 	x, y, z int
@@ -20,21 +19,21 @@ type state struct {
 	steps int
 }
 
-func getGrid(input io.Reader) (map[v2]bool, map[v2]uint32, map[v2]uint32, v2) {
-	grid := make(map[v2]bool)
-	doors := make(map[v2]uint32)
-	keys := make(map[v2]uint32)
-	var start v2
-
-	scanner := bufio.NewScanner(input)
-	y := 0
-	for scanner.Scan() {
-		line := scanner.Text()
-		for x, ch := range line {
+func getGrid(input io.Reader) (map[grid.Point]bool, map[grid.Point]uint32, map[grid.Point]uint32, grid.Point) {
+	open := make(map[grid.Point]bool)
+	doors := make(map[grid.Point]uint32)
+	keys := make(map[grid.Point]uint32)
+	var start grid.Point
+
+	g, err := grid.Read(input)
+	noerr(err)
+
+	for y, row := range g.Rows() {
+		for x, ch := range row {
 			if ch == '#' {
 				continue
 			}
-			pos := v2{x, y}
+			pos := grid.Point{X: x, Y: y}
 			if ch == '@' {
 				start = pos
 			} else if ch >= 'a' && ch <= 'z' {
@@ -43,27 +42,14 @@ func getGrid(input io.Reader) (map[v2]bool, map[v2]uint32, map[v2]uint32, v2) {
 			} else if ch >= 'A' && ch <= 'Z' {
 				doors[pos] = 1 << int(ch-'A')
 			}
-			grid[pos] = true
+			open[pos] = true
 		}
-		y++
 	}
 
-	err := scanner.Err()
-	noerr(err)
-
-	return grid, doors, keys, start
+	return open, doors, keys, start
 }
 
-var (
-	STEPS = []v2{
-		{1, 0},
-		{-1, 0},
-		{0, 1},
-		{0, -1},
-	}
-)
-
-func makeAllKeys(keys map[v2]uint32) uint32 {
+func makeAllKeys(keys map[grid.Point]uint32) uint32 {
 	res := uint32(0)
 	for _, k := range keys {
 		res |= k
@@ -71,12 +57,12 @@ func makeAllKeys(keys map[v2]uint32) uint32 {
 	return res
 }
 
-func solve(grid map[v2]bool, doors, keys map[v2]uint32, start v2) int {
+func solve(open map[grid.Point]bool, doors, keys map[grid.Point]uint32, start grid.Point) int {
 	allkeys := makeAllKeys(keys)
 	visited := make(map[v3]bool)
-	queue := []state{{pos: v3{start.x, start.y, 0}}}
+	queue := []state{{pos: v3{start.X, start.Y, 0}}}
 
-	//log.Printf("grid: %+v", grid)
+	//log.Printf("open: %+v", open)
 	//log.Printf("doors: %+v", doors)
 	//log.Printf("keys: %+v", keys)
 	//log.Printf("allKeys: %b", allkeys)
@@ -91,11 +77,11 @@ func solve(grid map[v2]bool, doors, keys map[v2]uint32, start v2) int {
 
 		visited[st.pos] = true
 
-		for _, s := range STEPS {
-			npos := v2{st.pos.x + s.x, st.pos.y + s.y}
-			next := v3{npos.x, npos.y, st.pos.z}
+		for _, s := range grid.Dirs4 {
+			npos := grid.Point{X: st.pos.x + s.X, Y: st.pos.y + s.Y}
+			next := v3{npos.X, npos.Y, st.pos.z}
 
-			if !grid[npos] {
+			if !open[npos] {
 				continue
 			}
 
@@ -133,13 +119,54 @@ func noerr(err error) {
 	}
 }
 
-func main() {
-	file, err := os.Open("INPUT")
-	noerr(err)
+func loadVault(path string, opts ...Option) (*Vault, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
 	defer file.Close()
+	g, err := readVault(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewVault(g, opts...)
+}
+
+func main() {
+	gen := flag.Bool("gen", false, "print a random vault instead of solving the input")
+	width := flag.Int("width", 31, "generated vault width")
+	height := flag.Int("height", 31, "generated vault height")
+	keys := flag.Int("keys", 10, "number of keys in the generated vault")
+	depth := flag.Int("depth", 3, "door dependency depth of the generated vault")
+	entrances := flag.Int("entrances", 1, "number of entrances in the generated vault")
+	seed := flag.Int64("seed", 1, "generator seed")
+	flag.Parse()
+
+	if *gen {
+		g, err := GenerateVault(GeneratorConfig{
+			Width:     *width,
+			Height:    *height,
+			Keys:      *keys,
+			Depth:     *depth,
+			Entrances: *entrances,
+			Seed:      *seed,
+		})
+		noerr(err)
+		noerr(WriteVault(os.Stdout, g))
+		return
+	}
 
-	grid, doors, keys, start := getGrid(file)
-	res := solve(grid, doors, keys, start)
+	vault, err := loadVault("INPUT")
+	noerr(err)
+	route, err := vault.SolveRoute()
+	noerr(err)
+	fmt.Print(vault.RenderRoute(route))
+	log.Printf("The result is: %d", route.Steps)
 
-	log.Printf("The result is: %d", res)
+	vault, err = loadVault("INPUT", WithSplitEntrance())
+	noerr(err)
+	route, err = vault.SolveRoute()
+	noerr(err)
+	fmt.Print(vault.RenderRoute(route))
+	log.Printf("The result with 4 robots is: %d", route.Steps)
 }
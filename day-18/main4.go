@@ -1,3 +1,5 @@
+//go:build ignore
+
 package main
 
 import (
@@ -78,7 +80,7 @@ func getAdjMatrix(input io.Reader) (map[vertex][]transit, []vertex, uint32) {
 		bs = append(bs, []byte(s))
 	}
 
-	//updateEntrances(bs)
+	updateEntrances(bs)
 
 	q := make([]vertex, 0, 1)
 	starts := make([]vertex, 0, 1)
@@ -241,7 +243,7 @@ func newState(origin []vertex, ix int, v vertex) []vertex {
 }
 
 func main() {
-	file, err := os.Open("INPUT-1")
+	file, err := os.Open("INPUT")
 	noerr(err)
 	defer file.Close()
 
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"sandbox/advent-of-code-2019/lib/grid"
+)
+
+// Pickup is a key collected on the route. Step is the total number of steps
+// made by all the robots up to this point.
+type Pickup struct {
+	Key   byte
+	Robot int
+	Pos   grid.Point
+	Step  int
+}
+
+type Route struct {
+	Steps   int
+	Pickups []Pickup
+	// every robot's walk cell by cell, starting at its entrance
+	Paths [][]grid.Point
+}
+
+// buildRoute replays the transits leading to the final search state. Keys are
+// reported in the order the robots walk over them, which might differ from
+// the transit targets when a walk passes another key on the way.
+func (v *Vault) buildRoute(moves map[robotsState]move, final robotsState, steps int) *Route {
+	trail := make([]move, 0, 1)
+	for state := final; ; {
+		m, ok := moves[state]
+		if !ok {
+			break
+		}
+		trail = append(trail, m)
+		state = m.prev
+	}
+
+	route := &Route{
+		Steps:   steps,
+		Pickups: make([]Pickup, 0, len(trail)),
+		Paths:   make([][]grid.Point, len(v.starts)),
+	}
+	for ix, start := range v.starts {
+		route.Paths[ix] = []grid.Point{start.pos}
+	}
+	var keys KeySet
+	step := 0
+	for ix := len(trail) - 1; ix >= 0; ix-- {
+		m := trail[ix]
+		for _, pos := range m.tr.path {
+			step++
+			route.Paths[m.robot] = append(route.Paths[m.robot], pos)
+			ch, _ := v.grid.At(pos)
+			if id, ok := v.alphabet.KeyID(ch); ok && !keys.Has(id) {
+				keys = keys.With(id)
+				route.Pickups = append(route.Pickups, Pickup{
+					Key:   ch,
+					Robot: m.robot,
+					Pos:   pos,
+					Step:  step,
+				})
+			}
+		}
+	}
+	return route
+}
+
+func robotMarker(robot int) byte {
+	if robot < 9 {
+		return byte('1' + robot)
+	}
+	return '*'
+}
+
+// RenderRoute draws the vault with the floor walked by every robot marked by
+// the robot number (starting with 1), followed by the order the keys were
+// collected in.
+func (v *Vault) RenderRoute(route *Route) string {
+	canvas := v.grid.Clone()
+	for robot, path := range route.Paths {
+		for _, pos := range path {
+			if ch, _ := canvas.At(pos); v.alphabet.IsFloor(ch) {
+				canvas.Set(pos, robotMarker(robot))
+			}
+		}
+	}
+
+	var buf strings.Builder
+	buf.WriteString(canvas.String())
+	buf.WriteByte('\n')
+	fmt.Fprintf(&buf, "%d steps, %d keys:\n", route.Steps, len(route.Pickups))
+	for ix, p := range route.Pickups {
+		fmt.Fprintf(&buf, "%3d. %c by robot %d at %+v, step %d\n", ix+1, p.Key, p.Robot+1, p.Pos, p.Step)
+	}
+	return buf.String()
+}
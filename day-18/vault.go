@@ -0,0 +1,325 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"sandbox/advent-of-code-2019/lib"
+	"sandbox/advent-of-code-2019/lib/grid"
+)
+
+var (
+	ErrNoEntrance  = errors.New("vault has no entrance")
+	ErrUnreachable = errors.New("not all keys could be collected")
+)
+
+type vertex struct {
+	ch  byte
+	pos grid.Point
+}
+
+func (v vertex) String() string {
+	return fmt.Sprintf("{ch: %c, pos: %+v}", v.ch, v.pos)
+}
+
+// transit is the shortest walk from one point of interest to a key: id is
+// the key ID, k holds the doors met on the way, l is the number of steps and
+// path lists the cells walked, the starting one excluded.
+type transit struct {
+	v    vertex
+	id   int
+	k    KeySet
+	l    int
+	path []grid.Point
+}
+
+func readVault(input io.Reader) (*grid.Grid, error) {
+	return grid.Read(input)
+}
+
+type Vault struct {
+	grid     *grid.Grid
+	alphabet *Alphabet
+	adj      map[vertex][]transit
+	ids      map[vertex]int
+	starts   []vertex
+	allKeys  KeySet
+}
+
+type vaultConfig struct {
+	alphabet      *Alphabet
+	splitEntrance bool
+}
+
+// Option tweaks the way a vault is read.
+type Option func(c *vaultConfig)
+
+// WithSplitEntrance replaces the single entrance and the open floor around it
+// with 4 separate entrances, turning the vault into the part two layout:
+//
+//	...      @#@
+//	.@.  =>  ###
+//	...      @#@
+func WithSplitEntrance() Option {
+	return func(c *vaultConfig) {
+		c.splitEntrance = true
+	}
+}
+
+// WithAlphabet makes the vault use a custom tile notation instead of the
+// default one.
+func WithAlphabet(alphabet *Alphabet) Option {
+	return func(c *vaultConfig) {
+		c.alphabet = alphabet
+	}
+}
+
+func NewVault(g *grid.Grid, opts ...Option) (*Vault, error) {
+	config := &vaultConfig{
+		alphabet: DefaultAlphabet(),
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	alphabet := config.alphabet
+	if config.splitEntrance {
+		var err error
+		if g, err = splitEntrance(g, alphabet); err != nil {
+			return nil, err
+		}
+	}
+
+	v := &Vault{
+		grid:     g,
+		alphabet: alphabet,
+		adj:      make(map[vertex][]transit),
+		ids:      make(map[vertex]int),
+	}
+	pois := make([]vertex, 0, 1)
+	for y, row := range g.Rows() {
+		for x, ch := range row {
+			pos := grid.Point{X: x, Y: y}
+			if alphabet.IsEntrance(ch) {
+				v.starts = append(v.starts, vertex{ch: ch, pos: pos})
+			}
+			if id, ok := alphabet.KeyID(ch); ok {
+				v.allKeys = v.allKeys.With(id)
+			}
+			if alphabet.IsEntrance(ch) || alphabet.IsKey(ch) {
+				pois = append(pois, vertex{ch: ch, pos: pos})
+			}
+		}
+	}
+	if len(v.starts) == 0 {
+		return nil, ErrNoEntrance
+	}
+	for _, from := range pois {
+		v.ids[from] = len(v.ids)
+		v.adj[from] = calcTransits(g, alphabet, from)
+	}
+	return v, nil
+}
+
+// splitEntrance returns a copy of the grid with the entrance split in 4. The
+// vault must have exactly one entrance surrounded by open floor.
+func splitEntrance(g *grid.Grid, alphabet *Alphabet) (*grid.Grid, error) {
+	var start *grid.Point
+	for y, row := range g.Rows() {
+		for x, ch := range row {
+			if !alphabet.IsEntrance(ch) {
+				continue
+			}
+			pos := grid.Point{X: x, Y: y}
+			if start != nil {
+				return nil, fmt.Errorf("can not split entrances: more than one entrance found at %+v and %+v", *start, pos)
+			}
+			start = &pos
+		}
+	}
+	if start == nil {
+		return nil, ErrNoEntrance
+	}
+	for _, s := range grid.Dirs8 {
+		np := start.Add(s)
+		ch, ok := g.At(np)
+		if !ok {
+			return nil, fmt.Errorf("can not split entrance at %+v: %+v is out of the vault", *start, np)
+		}
+		if !alphabet.IsFloor(ch) {
+			return nil, fmt.Errorf("can not split entrance at %+v: %+v is %q, not open floor", *start, np, ch)
+		}
+	}
+
+	res := g.Clone()
+	rows := res.Rows()
+	x, y := start.X, start.Y
+	e, w := alphabet.Entrance, alphabet.Wall
+	rows[y-1][x-1], rows[y-1][x], rows[y-1][x+1] = e, w, e
+	rows[y][x-1], rows[y][x], rows[y][x+1] = w, w, w
+	rows[y+1][x-1], rows[y+1][x], rows[y+1][x+1] = e, w, e
+	return res, nil
+}
+
+// calcTransits runs a BFS from a vertex and returns the walks to every key
+// reachable from it.
+func calcTransits(g *grid.Grid, alphabet *Alphabet, from vertex) []transit {
+	q := make([]transit, 0, 1)
+	ts := make([]transit, 0, 1)
+	q = append(q, transit{
+		v: from,
+		l: 0,
+	})
+	parents := make(map[grid.Point]grid.Point)
+	parents[from.pos] = from.pos
+	var h transit
+	for len(q) > 0 {
+		h, q = q[0], q[1:]
+		if id, ok := alphabet.DoorID(h.v.ch); ok {
+			h.k = h.k.With(id)
+		}
+		if id, ok := alphabet.KeyID(h.v.ch); ok && h.v != from {
+			h.id = id
+			h.path = backtrack(parents, from.pos, h.v.pos, h.l)
+			ts = append(ts, h)
+		}
+		for _, np := range g.Neighbors4(h.v.pos) {
+			if _, ok := parents[np]; ok {
+				continue
+			}
+			npch, _ := g.At(np)
+			if alphabet.IsWall(npch) {
+				continue
+			}
+			parents[np] = h.v.pos
+			q = append(q, transit{
+				v: vertex{
+					ch:  npch,
+					pos: np,
+				},
+				k: h.k,
+				l: h.l + 1,
+			})
+		}
+	}
+	return ts
+}
+
+func backtrack(parents map[grid.Point]grid.Point, from, to grid.Point, l int) []grid.Point {
+	path := make([]grid.Point, l)
+	for ptr := to; ptr != from; ptr = parents[ptr] {
+		l--
+		path[l] = ptr
+	}
+	return path
+}
+
+// robotsState is the search node: the vertex every robot stands on and the
+// keys collected so far.
+type robotsState struct {
+	robots string
+	keys   string
+}
+
+type searchNode struct {
+	robots []vertex
+	keys   KeySet
+}
+
+func (v *Vault) stateOf(node searchNode) robotsState {
+	var buf strings.Builder
+	for _, r := range node.robots {
+		id := v.ids[r]
+		buf.WriteByte(byte(id))
+		buf.WriteByte(byte(id >> 8))
+		buf.WriteByte(byte(id >> 16))
+	}
+	return robotsState{robots: buf.String(), keys: node.keys.Key()}
+}
+
+// move is the last transit taken to reach a search state.
+type move struct {
+	prev  robotsState
+	robot int
+	tr    *transit
+}
+
+// Solve runs Dijkstra over the robot positions and the collected keys using
+// the precomputed transits and returns the min number of steps to collect
+// all the keys.
+func (v *Vault) Solve() (int, error) {
+	route, err := v.SolveRoute()
+	if err != nil {
+		return -1, err
+	}
+	return route.Steps, nil
+}
+
+// SolveRoute does the same as Solve but returns the whole route.
+func (v *Vault) SolveRoute() (*Route, error) {
+	start := searchNode{robots: v.starts}
+	startState := v.stateOf(start)
+
+	dist := make(map[robotsState]int)
+	handles := make(map[robotsState]lib.Handle)
+	moves := make(map[robotsState]move)
+	done := make(map[robotsState]bool)
+	q := lib.NewIndexedBinHeap[searchNode](func(a, b int) bool { return a < b })
+
+	dist[startState] = 0
+	handles[startState] = q.Push(start, 0)
+
+	for q.Size() > 0 {
+		node, d, _ := q.Pop()
+		state := v.stateOf(node)
+		done[state] = true
+		if node.keys.Contains(v.allKeys) {
+			return v.buildRoute(moves, state, d), nil
+		}
+		for ix, r := range node.robots {
+			ts := v.adj[r]
+			for tix := range ts {
+				tr := &ts[tix]
+				if node.keys.Has(tr.id) {
+					// we already have this key
+					continue
+				}
+				// do we have enough keys to transit?
+				if !node.keys.Contains(tr.k) {
+					continue
+				}
+				next := searchNode{
+					robots: newState(node.robots, ix, tr.v),
+					keys:   node.keys.With(tr.id),
+				}
+				nstate := v.stateOf(next)
+				if done[nstate] {
+					continue
+				}
+				nd := d + tr.l
+				if od, ok := dist[nstate]; ok {
+					if nd >= od {
+						continue
+					}
+					dist[nstate] = nd
+					moves[nstate] = move{prev: state, robot: ix, tr: tr}
+					q.Update(handles[nstate], nd)
+					continue
+				}
+				dist[nstate] = nd
+				moves[nstate] = move{prev: state, robot: ix, tr: tr}
+				handles[nstate] = q.Push(next, nd)
+			}
+		}
+	}
+
+	return nil, ErrUnreachable
+}
+
+func newState(origin []vertex, ix int, v vertex) []vertex {
+	cp := make([]vertex, len(origin))
+	copy(cp, origin)
+	cp[ix] = v
+	return cp
+}
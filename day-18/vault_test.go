@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"sandbox/advent-of-code-2019/lib/grid"
+)
+
+func TestVault_Solve(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"INPUT-TST", 8},
+		{"INPUT-TST2", 86},
+		{"INPUT-TST3", 132},
+		{"INPUT-TST4", 136},
+		{"INPUT-TST5", 81},
+		{"INPUT-TST-1", 24},
+		{"INPUT-TST-2", 32},
+		{"INPUT-TST-3", 72},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			vault, err := loadVault(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			got, err := vault.Solve()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("Solve() for %q = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVault_Errors(t *testing.T) {
+	g, _ := readVault(strings.NewReader("#####\n#a.b#\n#####"))
+	if _, err := NewVault(g); err != ErrNoEntrance {
+		t.Errorf("NewVault() error = %v, want %v", err, ErrNoEntrance)
+	}
+
+	g, _ = readVault(strings.NewReader("#######\n#@.#.a#\n#######"))
+	vault, err := NewVault(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := vault.Solve(); err != ErrUnreachable {
+		t.Errorf("Solve() error = %v, want %v", err, ErrUnreachable)
+	}
+}
+
+func TestVault_SplitEntrance(t *testing.T) {
+	g, _ := readVault(strings.NewReader(strings.Join([]string{
+		"#######",
+		"#a.#Cd#",
+		"##...##",
+		"##.@.##",
+		"##...##",
+		"#cB#Ab#",
+		"#######",
+	}, "\n")))
+	vault, err := NewVault(g, WithSplitEntrance())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := len(vault.starts); got != 4 {
+		t.Errorf("len(starts) = %d, want 4", got)
+	}
+	if ch, _ := g.At(grid.Point{X: 3, Y: 3}); ch != '@' {
+		t.Errorf("the original grid must stay untouched")
+	}
+	got, err := vault.Solve()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got != 8 {
+		t.Errorf("Solve() = %d, want 8", got)
+	}
+}
+
+func TestVault_SplitEntrance_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"no entrance", "#####\n#...#\n#.a.#\n#...#\n#####"},
+		{"on the edge", "#@..#\n#...#\n#a..#"},
+		{"blocked", "#####\n#...#\n#.@a#\n#...#\n#####"},
+		{"wall", "#####\n#.#.#\n#.@.#\n#a..#\n#####"},
+		{"two entrances", "#########\n#...#...#\n#.@.#.@.#\n#...#a..#\n#########"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := readVault(strings.NewReader(tt.input))
+			if _, err := NewVault(g, WithSplitEntrance()); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestKeySet(t *testing.T) {
+	var s KeySet
+	if s.Has(0) || s.Len() != 0 {
+		t.Fatalf("the zero set must be empty")
+	}
+	s1 := s.With(3).With(100)
+	if !s1.Has(3) || !s1.Has(100) || s1.Has(64) || s1.Len() != 2 {
+		t.Errorf("unexpected set contents: %v", s1)
+	}
+	if s.Len() != 0 {
+		t.Errorf("With must not modify the original set")
+	}
+	s2 := KeySet{}.With(100)
+	if !s1.Contains(s2) || s2.Contains(s1) {
+		t.Errorf("unexpected Contains result for %v and %v", s1, s2)
+	}
+	if got := s2.Union(KeySet{}.With(3)); got.Key() != s1.Key() {
+		t.Errorf("Union() = %v, want %v", got, s1)
+	}
+	if (KeySet{1, 0}).Key() != (KeySet{1}).Key() {
+		t.Errorf("trailing empty words must not change the key")
+	}
+}
+
+func TestVault_CustomAlphabet(t *testing.T) {
+	keys := "abcdefghijklmnopqrstuvwxyz0123456789!$%&"
+	doors := "ABCDEFGHIJKLMNOPQRSTUVWXYZ()*+,-/:;<=>?^"
+	alphabet, err := NewAlphabet('#', ' ', '@', keys, doors)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// a corridor where every key opens the door right after the next key,
+	// the first key lies right behind the entrance
+	var row strings.Builder
+	row.WriteString("#~")
+	for ix := 1; ix < len(keys); ix++ {
+		row.WriteByte(keys[ix])
+		row.WriteByte(doors[ix-1])
+	}
+	row.WriteString("#")
+	line := row.String()
+	line = strings.Replace(line, "~", string(keys[0])+"@", 1)
+	wall := strings.Repeat("#", len(line))
+	g, _ := readVault(strings.NewReader(wall + "\n" + line + "\n" + wall))
+
+	vault, err := NewVault(g, WithAlphabet(alphabet))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := vault.allKeys.Len(); got != len(keys) {
+		t.Fatalf("allKeys.Len() = %d, want %d", got, len(keys))
+	}
+	got, err := vault.Solve()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// 1 step back for the first key, then 2 steps per every next key
+	if want := 1 + 2*(len(keys)-1); got != want {
+		t.Errorf("Solve() = %d, want %d", got, want)
+	}
+}
+
+func TestNewAlphabet_Errors(t *testing.T) {
+	if _, err := NewAlphabet('#', '.', '@', "ab", "A"); err == nil {
+		t.Errorf("expected an error for mismatching keys and doors")
+	}
+	if _, err := NewAlphabet('#', '.', '@', "a#", "AB"); err == nil {
+		t.Errorf("expected an error for a tile used twice")
+	}
+}
+
+// The route must be a valid walk: every step goes to an adjacent open cell,
+// doors are only passed with the key in hand and every key gets collected.
+func TestVault_SolveRoute(t *testing.T) {
+	for _, input := range []string{"INPUT-TST2", "INPUT-TST4", "INPUT-TST-3"} {
+		t.Run(input, func(t *testing.T) {
+			vault, err := loadVault(input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			route, err := vault.SolveRoute()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			steps := 0
+			for robot, path := range route.Paths {
+				if path[0] != vault.starts[robot].pos {
+					t.Errorf("robot %d starts at %+v, want %+v", robot, path[0], vault.starts[robot].pos)
+				}
+				for ix := 1; ix < len(path); ix++ {
+					d := path[ix].Sub(path[ix-1])
+					if d.X*d.X+d.Y*d.Y != 1 {
+						t.Fatalf("robot %d jumps from %+v to %+v", robot, path[ix-1], path[ix])
+					}
+					if ch, _ := vault.grid.At(path[ix]); vault.alphabet.IsWall(ch) {
+						t.Fatalf("robot %d walks into a wall at %+v", robot, path[ix])
+					}
+				}
+				steps += len(path) - 1
+			}
+			if steps != route.Steps {
+				t.Errorf("paths make %d steps, want %d", steps, route.Steps)
+			}
+			if got, want := len(route.Pickups), vault.allKeys.Len(); got != want {
+				t.Errorf("%d keys picked up, want %d", got, want)
+			}
+
+			// with a single robot the walk order is the pickup order, so the
+			// doors could be checked as well
+			if len(route.Paths) == 1 {
+				var keys KeySet
+				pickups := route.Pickups
+				for _, pos := range route.Paths[0] {
+					ch, _ := vault.grid.At(pos)
+					if id, ok := vault.alphabet.DoorID(ch); ok && !keys.Has(id) {
+						t.Fatalf("door %c at %+v passed without a key", ch, pos)
+					}
+					if len(pickups) > 0 && pickups[0].Pos == pos {
+						id, _ := vault.alphabet.KeyID(ch)
+						keys = keys.With(id)
+						pickups = pickups[1:]
+					}
+				}
+			}
+
+			out := vault.RenderRoute(route)
+			if !strings.Contains(out, fmt.Sprintf("%d steps", route.Steps)) {
+				t.Errorf("unexpected rendering:\n%s", out)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"log"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -88,45 +89,74 @@ func mustInt(s string) int {
 	return val
 }
 
-func IntersectSegments(s1, s2 Segment) *Point {
-	if s1 == s2 {
-		return nil
-	}
-	var vert, hor Segment
-	if s1.IsVertical() {
-		if s2.IsVertical() {
-			return nil
+// IntersectSegments returns the points shared by 2 segments: a single
+// crossing point for perpendicular ones and every point of the overlap for
+// collinear ones.
+func IntersectSegments(s1, s2 Segment) []Point {
+	if s1.IsVertical() == s2.IsVertical() {
+		if s1.IsVertical() {
+			if s1.p1.x != s2.p1.x {
+				return nil
+			}
+			var res []Point
+			for y := max(s1.p1.y, s2.p1.y); y <= min(s1.p2.y, s2.p2.y); y++ {
+				res = append(res, NewPoint(s1.p1.x, y))
+			}
+			return res
 		}
-		vert, hor = s1, s2
-	} else {
-		if !s2.IsVertical() {
+		if s1.p1.y != s2.p1.y {
 			return nil
 		}
+		var res []Point
+		for x := max(s1.p1.x, s2.p1.x); x <= min(s1.p2.x, s2.p2.x); x++ {
+			res = append(res, NewPoint(x, s1.p1.y))
+		}
+		return res
+	}
+	vert, hor := s1, s2
+	if !s1.IsVertical() {
 		vert, hor = s2, s1
 	}
 	if (hor.p1.x <= vert.p1.x && vert.p1.x <= hor.p2.x) &&
 		(vert.p1.y <= hor.p1.y && hor.p1.y <= vert.p2.y) {
-		p := NewPoint(vert.p1.x, hor.p1.y)
-		return &p
+		return []Point{NewPoint(vert.p1.x, hor.p1.y)}
 	}
 	return nil
 }
 
-func IntersectPaths(p1, p2 Path) []Point {
-	res := make([]Point, 0, 1)
+// IntersectPaths returns the points shared by at least 2 of the paths apart
+// from the origin they all start at, ordered by x and y.
+func IntersectPaths(paths ...Path) []Point {
 	zero := NewPoint(0, 0)
-	for _, s1 := range p1.segments {
-		for _, s2 := range p2.segments {
-			if point := IntersectSegments(s1, s2); point != nil {
-				if *point != zero {
-					res = append(res, *point)
-				}
-			}
+	res := make([]Point, 0, 1)
+	for point := range intersect(paths) {
+		if point != zero {
+			res = append(res, point)
 		}
 	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].x != res[j].x {
+			return res[i].x < res[j].x
+		}
+		return res[i].y < res[j].y
+	})
 	return res
 }
 
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 func abs(v int) int {
 	if v < 0 {
 		return -v
@@ -175,13 +205,18 @@ func main() {
 		paths = append(paths, path)
 		log.Printf("pparsed path: %+v", path)
 	}
-	p1, p2 := paths[0], paths[1]
-	points := IntersectPaths(p1, p2)
+	points := IntersectPaths(paths...)
 	log.Printf("Intersections: %+v", points)
 
 	minDist := 0
 	for _, point := range points {
-		dist := DistanceToPoint(p1, point) + DistanceToPoint(p2, point)
+		dist := 0
+		for _, path := range paths {
+			// only the wires passing through the point count
+			if d := DistanceToPoint(path, point); d >= 0 {
+				dist += d
+			}
+		}
 		if minDist == 0 || minDist > dist {
 			minDist = dist
 		}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// bruteIntersect intersects every pair of segments of different paths.
+func bruteIntersect(paths []Path) map[Point]bool {
+	res := make(map[Point]bool)
+	for i := range paths {
+		for j := i + 1; j < len(paths); j++ {
+			for _, s1 := range paths[i].segments {
+				for _, s2 := range paths[j].segments {
+					for _, p := range IntersectSegments(s1, s2) {
+						if p != NewPoint(0, 0) {
+							res[p] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return res
+}
+
+func randomPath(rnd *rand.Rand, moves int) Path {
+	chunks := make([]string, 0, moves)
+	for i := 0; i < moves; i++ {
+		chunks = append(chunks, fmt.Sprintf("%c%d", "RLUD"[rnd.Intn(4)], rnd.Intn(10)))
+	}
+	return NewPath(strings.Join(chunks, ","))
+}
+
+func TestIntersectSegments(t *testing.T) {
+	cases := []struct {
+		name   string
+		s1, s2 Segment
+		want   []Point
+	}{
+		{"crossing", NewSegment(NewPoint(0, -2), NewPoint(0, 2)), NewSegment(NewPoint(-1, 1), NewPoint(3, 1)), []Point{{0, 1}}},
+		{"apart", NewSegment(NewPoint(0, -2), NewPoint(0, 2)), NewSegment(NewPoint(1, 1), NewPoint(3, 1)), nil},
+		{"horizontal overlap", NewSegment(NewPoint(0, 1), NewPoint(4, 1)), NewSegment(NewPoint(6, 1), NewPoint(2, 1)), []Point{{2, 1}, {3, 1}, {4, 1}}},
+		{"vertical overlap", NewSegment(NewPoint(3, 0), NewPoint(3, 5)), NewSegment(NewPoint(3, 5), NewPoint(3, 7)), []Point{{3, 5}}},
+		{"parallel", NewSegment(NewPoint(3, 0), NewPoint(3, 5)), NewSegment(NewPoint(4, 0), NewPoint(4, 5)), nil},
+	}
+	for _, c := range cases {
+		if got := IntersectSegments(c.s1, c.s2); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestIntersectPaths(t *testing.T) {
+	got := IntersectPaths(NewPath("R8,U5,L5,D3"), NewPath("U7,R6,D4,L4"))
+	want := []Point{{3, 3}, {6, 5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// the second wire runs along the first one from x=2 and along the third
+	// one up to y=1, the third one crosses both
+	got = IntersectPaths(NewPath("R5"), NewPath("U1,R2,D1,R2"), NewPath("U2,R1,D4"))
+	want = []Point{{0, 1}, {1, 0}, {1, 1}, {2, 0}, {3, 0}, {4, 0}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestIntersectPaths_Brute(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	for i := 0; i < 200; i++ {
+		paths := make([]Path, 2+rnd.Intn(3))
+		for ix := range paths {
+			paths[ix] = randomPath(rnd, 1+rnd.Intn(20))
+		}
+		want := bruteIntersect(paths)
+		got := IntersectPaths(paths...)
+		if len(got) != len(want) {
+			t.Fatalf("paths %+v: got %d points, want %d", paths, len(got), len(want))
+		}
+		for _, p := range got {
+			if !want[p] {
+				t.Fatalf("paths %+v: unexpected point %+v", paths, p)
+			}
+		}
+	}
+}
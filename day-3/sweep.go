@@ -0,0 +1,172 @@
+package main
+
+import (
+	"sort"
+)
+
+// wireSegment is a segment tagged with the index of the wire it belongs to.
+type wireSegment struct {
+	Segment
+	wire int
+}
+
+// crossings collects the points shared by different wires.
+type crossings map[Point]map[int]bool
+
+func (c crossings) add(p Point, w1, w2 int) {
+	if w1 == w2 {
+		return
+	}
+	wires, ok := c[p]
+	if !ok {
+		wires = make(map[int]bool)
+		c[p] = wires
+	}
+	wires[w1] = true
+	wires[w2] = true
+}
+
+// interval is a segment projected on the axis it lies along.
+type interval struct {
+	from, to int
+	wire     int
+}
+
+// overlaps reports every point shared by intervals of different wires lying
+// on the same line. The intervals are swept from the lowest start: when one
+// starts, every still active interval overlaps it up to the closest end.
+func overlaps(ivs []interval, report func(v, w1, w2 int)) {
+	sort.Slice(ivs, func(i, j int) bool {
+		return ivs[i].from < ivs[j].from
+	})
+	var active []interval
+	for _, iv := range ivs {
+		left := active[:0]
+		for _, a := range active {
+			if a.to >= iv.from {
+				left = append(left, a)
+			}
+		}
+		active = left
+		for _, a := range active {
+			if a.wire == iv.wire {
+				continue
+			}
+			to := min(a.to, iv.to)
+			for v := iv.from; v <= to; v++ {
+				report(v, a.wire, iv.wire)
+			}
+		}
+		active = append(active, iv)
+	}
+}
+
+// sweepEvent is a vertical line of the sweep: horizontal segments start or
+// end there, vertical ones are queried against the active horizontal ones.
+type sweepEvent struct {
+	x    int
+	kind int
+	seg  wireSegment
+}
+
+const (
+	EVENT_START = iota
+	EVENT_QUERY
+	EVENT_END
+)
+
+// activeSet keeps the active horizontal segments sorted by y.
+type activeSet []wireSegment
+
+func (as activeSet) search(y int) int {
+	return sort.Search(len(as), func(i int) bool {
+		return as[i].p1.y >= y
+	})
+}
+
+func (as *activeSet) insert(s wireSegment) {
+	ix := (*as).search(s.p1.y)
+	*as = append(*as, wireSegment{})
+	copy((*as)[ix+1:], (*as)[ix:])
+	(*as)[ix] = s
+}
+
+func (as *activeSet) remove(s wireSegment) {
+	for ix := (*as).search(s.p1.y); ix < len(*as); ix++ {
+		if (*as)[ix] == s {
+			*as = append((*as)[:ix], (*as)[ix+1:]...)
+			return
+		}
+	}
+}
+
+// perpendicular reports the crossings of horizontal and vertical segments
+// sweeping a vertical line from left to right. At every x the horizontal
+// segments starting there are added before the vertical ones are looked up
+// and removed after, so the touching ends count as crossings.
+func perpendicular(hor, vert []wireSegment, c crossings) {
+	events := make([]sweepEvent, 0, 2*len(hor)+len(vert))
+	for _, s := range hor {
+		events = append(events,
+			sweepEvent{x: s.p1.x, kind: EVENT_START, seg: s},
+			sweepEvent{x: s.p2.x, kind: EVENT_END, seg: s})
+	}
+	for _, s := range vert {
+		events = append(events, sweepEvent{x: s.p1.x, kind: EVENT_QUERY, seg: s})
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].x != events[j].x {
+			return events[i].x < events[j].x
+		}
+		return events[i].kind < events[j].kind
+	})
+
+	var active activeSet
+	for _, ev := range events {
+		switch ev.kind {
+		case EVENT_START:
+			active.insert(ev.seg)
+		case EVENT_END:
+			active.remove(ev.seg)
+		case EVENT_QUERY:
+			v := ev.seg
+			for ix := active.search(v.p1.y); ix < len(active) && active[ix].p1.y <= v.p2.y; ix++ {
+				c.add(NewPoint(v.p1.x, active[ix].p1.y), v.wire, active[ix].wire)
+			}
+		}
+	}
+}
+
+// intersect finds the points shared by at least 2 of the wires: the crossings
+// of perpendicular segments and every point of the collinear overlaps.
+func intersect(paths []Path) crossings {
+	var hor, vert []wireSegment
+	horLines := make(map[int][]interval)
+	vertLines := make(map[int][]interval)
+	for wire, path := range paths {
+		for _, s := range path.segments {
+			ws := wireSegment{Segment: s, wire: wire}
+			if s.IsVertical() {
+				vert = append(vert, ws)
+				vertLines[s.p1.x] = append(vertLines[s.p1.x], interval{s.p1.y, s.p2.y, wire})
+			} else {
+				hor = append(hor, ws)
+				horLines[s.p1.y] = append(horLines[s.p1.y], interval{s.p1.x, s.p2.x, wire})
+			}
+		}
+	}
+
+	c := make(crossings)
+	perpendicular(hor, vert, c)
+	for y, ivs := range horLines {
+		overlaps(ivs, func(x, w1, w2 int) {
+			c.add(NewPoint(x, y), w1, w2)
+		})
+	}
+	for x, ivs := range vertLines {
+		overlaps(ivs, func(y, w1, w2 int) {
+			c.add(NewPoint(x, y), w1, w2)
+		})
+	}
+	return c
+}
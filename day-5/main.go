@@ -120,11 +120,11 @@ func compute(program []int, input <-chan int, output chan<- int) {
 			_, mode1, mode2 := opDuo(program[pcnt])
 			log.Printf("duo mode: %d, %d", mode1, mode2)
 			cond, dest := readval(program, pcnt+1, mode1), readval(program, pcnt+2, mode2)
-			if cond > 0 {
-				log.Printf("cond %d > 0, jump to %d", cond, dest)
+			if cond != 0 {
+				log.Printf("cond %d != 0, jump to %d", cond, dest)
 				pcnt = dest
 			} else {
-				log.Printf("cond %d is not > 0, continue", cond)
+				log.Printf("cond %d is 0, continue", cond)
 				pcnt += 3
 			}
 		case CODE_JMPZ:
@@ -1,72 +1,45 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"runtime"
 	"strings"
-	"sync"
 )
 
-type Amplifier struct {
-	program []int
-	in      <-chan int
-	out     chan<- int
-}
-
-func NewAmplifier(program []int, in <-chan int, out chan<- int) *Amplifier {
-	return &Amplifier{
-		program: program,
-		in:      in,
-		out:     out,
-	}
-}
-
-func (a *Amplifier) Proc() {
-	Compute(a.program, a.in, a.out)
-}
-
-func rotateRight(slc []int) {
-	head := slc[0]
-	copy(slc, slc[1:])
-	slc[len(slc)-1] = head
-}
-
 func cpArr(arr []int) []int {
 	res := make([]int, len(arr))
 	copy(res, arr)
 	return res
 }
 
-func permutate(seq []int) <-chan []int {
-	ch := make(chan []int)
-	var perm func(int)
-	perm = func(k int) {
-		if k == 1 {
-			ch <- cpArr(seq)
-		} else {
-			for i := 0; i < k; i++ {
-				perm(k - 1)
-				if k%2 > 0 {
-					seq[0], seq[k-1] = seq[k-1], seq[0]
-				} else {
-					seq[i], seq[k-1] = seq[k-1], seq[i]
-				}
-			}
-
-		}
-		if k == len(seq) {
-			close(ch)
-		}
+func main() {
+	input := flag.String("input", "INPUT", "intcode program file")
+	phasesFlag := flag.String("phases", "5-9", "phase settings, like \"5-9\" or \"0,2,4-6\"")
+	amps := flag.Int("amps", 0, "number of amplifiers, defaults to the number of phases")
+	loop := flag.Bool("loop", true, "connect the last amplifier back to the first one")
+	workers := flag.Int("workers", runtime.GOMAXPROCS(0), "number of search workers")
+	flag.Parse()
 
+	phases, err := parsePhases(*phasesFlag)
+	if err != nil {
+		log.Fatalf("Failed to parse phases: %s", err)
+	}
+	if *amps == 0 {
+		*amps = len(phases)
+	}
+	if *workers > runtime.GOMAXPROCS(0) {
+		*workers = runtime.GOMAXPROCS(0)
+	}
+	topology := TOPOLOGY_CHAIN
+	if *loop {
+		topology = TOPOLOGY_LOOP
 	}
-	go perm(len(seq))
-	return ch
-}
 
-func main() {
-	file, err := os.Open("INPUT")
+	file, err := os.Open(*input)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to open input file: %s", err))
 	}
@@ -75,46 +48,17 @@ func main() {
 		log.Fatalf("Failed to read input file: %s", err)
 	}
 	rawProgram := strings.Trim(string(data), "\n\r\t")
-	program, err := ParseProgram(rawProgram)
+	program, err := ParseProgram64(rawProgram)
 	if err != nil {
 		log.Fatalf("Failed to parse program: %s", err)
 	}
 
-	maxThurst := 0
-	var maxSettings []int
-	for settings := range permutate([]int{5, 6, 7, 8, 9}) {
-		log.Printf("settings: %+v", settings)
-		ampls := make([]*Amplifier, 0, len(settings))
-		var in, out chan int
-		wire := make(chan int, 2)
-		out = wire
-		var wg sync.WaitGroup
-		for ix, set := range settings {
-			in = out
-			if ix == len(settings)-1 {
-				out = wire
-			} else {
-				out = make(chan int, 2)
-			}
-			in <- set
-			ampl := NewAmplifier(CopyProgram(program), in, out)
-			ampls = append(ampls, ampl)
-			wg.Add(1)
-			go func() {
-				ampl.Proc()
-				wg.Done()
-			}()
-		}
-		wire <- 0
-		wg.Wait()
-		thurst := <-wire
-		if thurst > maxThurst {
-			maxThurst = thurst
-			maxSettings = settings
-		}
-		log.Printf("thurst: %d", thurst)
+	res, err := SearchPhases(program, topology, phases, *amps, *workers)
+	if err != nil {
+		log.Fatalf("Failed to run amplifiers: %s", err)
 	}
 
-	log.Printf("Max thurst: %d", maxThurst)
-	log.Printf("MaxSettings: %+v", maxSettings)
+	log.Printf("Max thurst: %d", res.Thurst)
+	log.Printf("MaxSettings: %+v", res.Settings)
+	log.Printf("Checked %d settings with %d workers in %s", res.Checked, res.Workers, res.Elapsed)
 }
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+type Topology int
+
+const (
+	// Every node feeds the next one, the last node's output is the result.
+	TOPOLOGY_CHAIN Topology = iota
+	// Same as the chain, but the last node feeds its output back to the first
+	// one. The result is the last value emitted before the network halts.
+	TOPOLOGY_LOOP
+)
+
+var (
+	ErrNoNodes  = errors.New("network has no nodes")
+	ErrNoOutput = errors.New("network produced no output")
+)
+
+type Network struct {
+	program  []int64
+	topology Topology
+}
+
+func NewNetwork(program []int64, topology Topology) *Network {
+	return &Network{
+		program:  program,
+		topology: topology,
+	}
+}
+
+// Run starts one node per entry in inputs, every node running its own copy of
+// the program. inputs[i] is delivered to node i before anything coming from
+// the upstream node, so phase settings and the initial signal are both passed
+// this way.
+func (n *Network) Run(inputs [][]int64) (int64, error) {
+	size := len(inputs)
+	if size == 0 {
+		return 0, ErrNoNodes
+	}
+
+	programs := make([]*Program, size)
+	ins := make([]chan int64, size)
+	outs := make([]chan int64, size)
+	dones := make([]chan struct{}, size)
+	for ix := 0; ix < size; ix++ {
+		programs[ix] = NewProgram(CopyProgram64(n.program))
+		ins[ix] = make(chan int64, len(inputs[ix])+1)
+		outs[ix] = make(chan int64)
+		dones[ix] = make(chan struct{})
+	}
+
+	var back chan int64
+	if n.topology == TOPOLOGY_LOOP {
+		back = make(chan int64)
+	}
+
+	var wg sync.WaitGroup
+	for ix := 0; ix < size; ix++ {
+		var src <-chan int64
+		if ix > 0 {
+			src = outs[ix-1]
+		} else if back != nil {
+			src = back
+		}
+		wg.Add(2)
+		go func(ix int) {
+			defer wg.Done()
+			feed(ins[ix], inputs[ix], src, dones[ix])
+		}(ix)
+		go func(ix int) {
+			defer wg.Done()
+			Compute(programs[ix], ins[ix], outs[ix])
+			close(dones[ix])
+			close(outs[ix])
+		}(ix)
+	}
+
+	var res int64
+	received := false
+	for v := range outs[size-1] {
+		res, received = v, true
+		if back != nil {
+			select {
+			case back <- v:
+			case <-dones[0]:
+			}
+		}
+	}
+	if back != nil {
+		close(back)
+	}
+	wg.Wait()
+
+	for ix, program := range programs {
+		if program.Status() == PROGRAM_ERR {
+			return 0, fmt.Errorf("node %d failed: %s", ix, program.Err())
+		}
+	}
+	if !received {
+		return 0, ErrNoOutput
+	}
+	return res, nil
+}
+
+// feed delivers initial followed by everything coming from src to dst. Once
+// the consuming node is done, the rest of src is drained so the upstream node
+// never blocks on its output.
+func feed(dst chan<- int64, initial []int64, src <-chan int64, done <-chan struct{}) {
+	defer close(dst)
+	for _, v := range initial {
+		select {
+		case dst <- v:
+		case <-done:
+			drain(src)
+			return
+		}
+	}
+	if src == nil {
+		return
+	}
+	for v := range src {
+		select {
+		case dst <- v:
+		case <-done:
+			drain(src)
+			return
+		}
+	}
+}
+
+func drain(src <-chan int64) {
+	if src == nil {
+		return
+	}
+	for range src {
+	}
+}
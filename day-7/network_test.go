@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func mustProgram(t *testing.T, s string) []int64 {
+	t.Helper()
+	program, err := ParseProgram64(s)
+	if err != nil {
+		t.Fatalf("failed to parse program: %s", err)
+	}
+	return program
+}
+
+func TestNetwork_Chain(t *testing.T) {
+	cases := []struct {
+		program  string
+		settings []int
+		want     int64
+	}{
+		{"3,15,3,16,1002,16,10,16,1,16,15,15,4,15,99,0,0", []int{4, 3, 2, 1, 0}, 43210},
+		{"3,23,3,24,1002,24,10,24,1002,23,-1,23,101,5,23,23,1,24,23,23,4,23,99,0,0", []int{0, 1, 2, 3, 4}, 54321},
+		{"3,31,3,32,1002,32,10,32,1001,31,-2,31,1007,31,0,33,1002,33,7,33,1,33,31,31,1,32,31,31,4,31,99,0,0,0", []int{1, 0, 4, 3, 2}, 65210},
+	}
+	for _, c := range cases {
+		network := NewNetwork(mustProgram(t, c.program), TOPOLOGY_CHAIN)
+		got, err := network.Run(settingsToInputs(c.settings))
+		if err != nil {
+			t.Errorf("settings %+v: %s", c.settings, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("settings %+v: got %d, want %d", c.settings, got, c.want)
+		}
+	}
+}
+
+func TestNetwork_Loop(t *testing.T) {
+	cases := []struct {
+		program  string
+		settings []int
+		want     int64
+	}{
+		{"3,26,1001,26,-4,26,3,27,1002,27,2,27,1,27,26,27,4,27,1001,28,-1,28,1005,28,6,99,0,0,5", []int{9, 8, 7, 6, 5}, 139629729},
+		{"3,52,1001,52,-5,52,3,53,1,52,56,54,1007,54,5,55,1005,55,26,1001,54,-5,54,1105,1,12,1,53,54,53,1008,54,0,55,1001,55,1,55,2,53,55,53,4,53,1001,56,-1,56,1005,56,6,99,0,0,0,0,10", []int{9, 7, 8, 5, 6}, 18216},
+	}
+	for _, c := range cases {
+		network := NewNetwork(mustProgram(t, c.program), TOPOLOGY_LOOP)
+		got, err := network.Run(settingsToInputs(c.settings))
+		if err != nil {
+			t.Errorf("settings %+v: %s", c.settings, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("settings %+v: got %d, want %d", c.settings, got, c.want)
+		}
+	}
+}
+
+func TestNetwork_Errors(t *testing.T) {
+	network := NewNetwork(mustProgram(t, "3,0,99"), TOPOLOGY_CHAIN)
+	if _, err := network.Run(nil); err != ErrNoNodes {
+		t.Errorf("no nodes: got %v, want %v", err, ErrNoNodes)
+	}
+	if _, err := network.Run([][]int64{{1}, {2}}); err != ErrNoOutput {
+		t.Errorf("no output: got %v, want %v", err, ErrNoOutput)
+	}
+
+	// outputs 7 for the phase 0, runs into an unknown opcode for the rest
+	failing := mustProgram(t, "3,9,1005,9,8,104,7,99,42,0")
+	for _, topology := range []Topology{TOPOLOGY_CHAIN, TOPOLOGY_LOOP} {
+		network := NewNetwork(failing, topology)
+		if got, err := network.Run([][]int64{{0}, {0}}); err != nil || got != 7 {
+			t.Errorf("topology %d: got %d, %v, want 7", topology, got, err)
+		}
+		_, err := network.Run([][]int64{{0}, {1}, {0}})
+		if err == nil || errors.Is(err, ErrNoOutput) || !strings.Contains(err.Error(), "node 1 failed") {
+			t.Errorf("topology %d: got %v, want node 1 to fail", topology, err)
+		}
+	}
+}
+
+func TestCompute_JumpIfTrue(t *testing.T) {
+	// outputs 1 unless the input is 0
+	program := mustProgram(t, "3,12,1005,12,9,104,0,99,0,104,1,99,0")
+	for _, c := range []struct {
+		input, want int64
+	}{{0, 0}, {5, 1}, {-5, 1}} {
+		network := NewNetwork(program, TOPOLOGY_CHAIN)
+		got, err := network.Run([][]int64{{c.input}})
+		if err != nil {
+			t.Errorf("input %d: %s", c.input, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("input %d: got %d, want %d", c.input, got, c.want)
+		}
+	}
+}
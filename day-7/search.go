@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sandbox/advent-of-code-2019/lib/combinatorics"
+)
+
+type SearchResult struct {
+	Settings []int
+	Thurst   int64
+	Checked  int
+	Workers  int
+	Elapsed  time.Duration
+}
+
+// SearchPhases tries every ordering of amps distinct settings picked from
+// phases and returns the one yielding the max thurst. The orderings are spread
+// across workers goroutines, each of them running its own network on a private
+// copy of the program.
+func SearchPhases(program []int64, topology Topology, phases []int, amps, workers int) (*SearchResult, error) {
+	if amps <= 0 || amps > len(phases) {
+		return nil, fmt.Errorf("can not pick %d amplifier settings out of %d phases", amps, len(phases))
+	}
+	if workers <= 0 {
+		return nil, fmt.Errorf("invalid number of workers: %d", workers)
+	}
+
+	start := time.Now()
+	jobs := make(chan []int, workers)
+	go func() {
+		arrange(phases, amps, func(settings []int) {
+			jobs <- cpArr(settings)
+		})
+		close(jobs)
+	}()
+
+	results := make([]SearchResult, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for ix := 0; ix < workers; ix++ {
+		wg.Add(1)
+		go func(ix int) {
+			defer wg.Done()
+			best := &results[ix]
+			network := NewNetwork(CopyProgram64(program), topology)
+			for settings := range jobs {
+				// keep draining the jobs after a failure so the
+				// producer never gets stuck
+				if errs[ix] != nil {
+					continue
+				}
+				thurst, err := network.Run(settingsToInputs(settings))
+				if err != nil {
+					errs[ix] = fmt.Errorf("settings %+v: %s", settings, err)
+					continue
+				}
+				best.Checked++
+				if best.Settings == nil || thurst > best.Thurst {
+					best.Settings = settings
+					best.Thurst = thurst
+				}
+			}
+		}(ix)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := &SearchResult{Workers: workers}
+	for _, best := range results {
+		res.Checked += best.Checked
+		if best.Settings == nil {
+			continue
+		}
+		if res.Settings == nil || best.Thurst > res.Thurst {
+			res.Settings = best.Settings
+			res.Thurst = best.Thurst
+		}
+	}
+	res.Elapsed = time.Since(start)
+	return res, nil
+}
+
+func settingsToInputs(settings []int) [][]int64 {
+	inputs := make([][]int64, 0, len(settings))
+	for _, set := range settings {
+		inputs = append(inputs, []int64{int64(set)})
+	}
+	inputs[0] = append(inputs[0], 0)
+	return inputs
+}
+
+// arrange calls emit with every ordered selection of k distinct elements of
+// seq. The slice passed to emit is reused between the calls.
+func arrange(seq []int, k int, emit func([]int)) {
+	combinatorics.Combinations(seq, k, func(picked []int) bool {
+		combinatorics.Permutations(picked, func(settings []int) bool {
+			emit(settings)
+			return true
+		})
+		return true
+	})
+}
+
+// parsePhases accepts a comma-separated list of phases and phase ranges,
+// like "5-9" or "0,1,4-6". The phases are not negative, so a dash always
+// separates the range bounds.
+func parsePhases(s string) ([]int, error) {
+	res := make([]int, 0, 1)
+	seen := make(map[int]bool)
+	for _, chunk := range strings.Split(s, ",") {
+		chunk = strings.TrimSpace(chunk)
+		if len(chunk) == 0 {
+			return nil, errors.New("empty phase")
+		}
+		from, to := chunk, chunk
+		if ix := strings.Index(chunk, "-"); ix >= 0 {
+			from, to = chunk[:ix], chunk[ix+1:]
+		}
+		lo, err := parsePhase(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid phase range %q: %s", chunk, err)
+		}
+		hi, err := parsePhase(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid phase range %q: %s", chunk, err)
+		}
+		if lo > hi {
+			return nil, fmt.Errorf("invalid phase range: %s", chunk)
+		}
+		for v := lo; v <= hi; v++ {
+			if seen[v] {
+				return nil, fmt.Errorf("duplicate phase: %d", v)
+			}
+			seen[v] = true
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
+
+func parsePhase(s string) (int, error) {
+	if len(s) == 0 {
+		return 0, errors.New("missing phase")
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("negative phase: %d", v)
+	}
+	return v, nil
+}
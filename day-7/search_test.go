@@ -0,0 +1,103 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParsePhases(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []int
+	}{
+		{"5-9", []int{5, 6, 7, 8, 9}},
+		{"0,1,4-6", []int{0, 1, 4, 5, 6}},
+		{" 3 , 7-7", []int{3, 7}},
+	}
+	for _, c := range cases {
+		got, err := parsePhases(c.in)
+		if err != nil {
+			t.Errorf("%q: %s", c.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q: got %+v, want %+v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestParsePhases_Invalid(t *testing.T) {
+	for _, in := range []string{
+		"", "1,,2", "5-", "-5", "-1-3", "1--3", "x", "1-x", "9-5", "1,1", "1-3,3-5",
+	} {
+		if got, err := parsePhases(in); err == nil {
+			t.Errorf("%q: got %+v, want an error", in, got)
+		}
+	}
+}
+
+func TestArrange(t *testing.T) {
+	var got [][]int
+	arrange([]int{1, 2, 3}, 2, func(settings []int) {
+		got = append(got, cpArr(settings))
+	})
+	sort.Slice(got, func(i, j int) bool {
+		if got[i][0] != got[j][0] {
+			return got[i][0] < got[j][0]
+		}
+		return got[i][1] < got[j][1]
+	})
+	want := [][]int{{1, 2}, {1, 3}, {2, 1}, {2, 3}, {3, 1}, {3, 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestSearchPhases(t *testing.T) {
+	chain := mustProgram(t, "3,31,3,32,1002,32,10,32,1001,31,-2,31,1007,31,0,33,1002,33,7,33,1,33,31,31,1,32,31,31,4,31,99,0,0,0")
+	loop := mustProgram(t, "3,26,1001,26,-4,26,3,27,1002,27,2,27,1,27,26,27,4,27,1001,28,-1,28,1005,28,6,99,0,0,5")
+	cases := []struct {
+		program  []int64
+		topology Topology
+		phases   []int
+		want     []int
+		thurst   int64
+	}{
+		{chain, TOPOLOGY_CHAIN, []int{0, 1, 2, 3, 4}, []int{1, 0, 4, 3, 2}, 65210},
+		{loop, TOPOLOGY_LOOP, []int{5, 6, 7, 8, 9}, []int{9, 8, 7, 6, 5}, 139629729},
+	}
+	for _, c := range cases {
+		for _, workers := range []int{1, 4} {
+			res, err := SearchPhases(c.program, c.topology, c.phases, len(c.phases), workers)
+			if err != nil {
+				t.Errorf("%d workers: %s", workers, err)
+				continue
+			}
+			if !reflect.DeepEqual(res.Settings, c.want) || res.Thurst != c.thurst {
+				t.Errorf("%d workers: got %+v -> %d, want %+v -> %d", workers, res.Settings, res.Thurst, c.want, c.thurst)
+			}
+			if res.Checked != 120 {
+				t.Errorf("%d workers: checked %d settings, want 120", workers, res.Checked)
+			}
+		}
+	}
+}
+
+func TestSearchPhases_Invalid(t *testing.T) {
+	program := mustProgram(t, "3,0,4,0,99")
+	if _, err := SearchPhases(program, TOPOLOGY_CHAIN, []int{0, 1}, 3, 1); err == nil {
+		t.Errorf("more amps than phases: want an error")
+	}
+	if _, err := SearchPhases(program, TOPOLOGY_CHAIN, []int{0, 1}, 0, 1); err == nil {
+		t.Errorf("no amps: want an error")
+	}
+	if _, err := SearchPhases(program, TOPOLOGY_CHAIN, []int{0, 1}, 2, 0); err == nil {
+		t.Errorf("no workers: want an error")
+	}
+	// the program fails on any phase but 0
+	failing := mustProgram(t, "3,9,1005,9,8,104,7,99,42,0")
+	if _, err := SearchPhases(failing, TOPOLOGY_CHAIN, []int{0, 1, 2}, 2, 2); err == nil {
+		t.Errorf("failing program: want an error")
+	}
+}
@@ -1,108 +1,186 @@
-package main
+package lib
 
-type Comparator func(v1, v2 interface{}) bool
+// heapIndex keeps track of element positions in the heap so they could be
+// looked up by value. It is only available for comparable element types.
+type heapIndex[T any] interface {
+	add(v T, ix int)
+	get(v T) (int, bool)
+	del(v T, ix int)
+	swap(v1 T, ix1 int, v2 T, ix2 int)
+}
+
+// mapIndex maps every value to all the positions it occupies, so equal values
+// pushed more than once do not clobber each other. The values are boxed, so
+// the same index serves IfaceBinHeap, whose values must be comparable at run
+// time.
+type mapIndex[T any] map[interface{}][]int
 
-type BinHeap struct {
-	elements   []interface{}
-	comparator Comparator
-	index      map[interface{}]int
+func (m mapIndex[T]) add(v T, ix int) {
+	m[v] = append(m[v], ix)
 }
 
-func NewBinHeap(cmp Comparator) *BinHeap {
-	return &BinHeap{
-		elements:   make([]interface{}, 0, 1),
-		comparator: cmp,
-		index:      make(map[interface{}]int),
+func (m mapIndex[T]) get(v T) (int, bool) {
+	ixs, ok := m[v]
+	if !ok {
+		return -1, false
 	}
+	return ixs[0], true
 }
 
-func (h *BinHeap) Size() int {
+func (m mapIndex[T]) del(v T, at int) {
+	ixs := m[v]
+	for i, ix := range ixs {
+		if ix == at {
+			ixs[i] = ixs[len(ixs)-1]
+			ixs = ixs[:len(ixs)-1]
+			break
+		}
+	}
+	if len(ixs) == 0 {
+		delete(m, v)
+		return
+	}
+	m[v] = ixs
+}
+
+func (m mapIndex[T]) swap(v1 T, ix1 int, v2 T, ix2 int) {
+	if interface{}(v1) == interface{}(v2) {
+		return
+	}
+	m.move(v1, ix1, ix2)
+	m.move(v2, ix2, ix1)
+}
+
+func (m mapIndex[T]) move(v T, from, to int) {
+	ixs := m[v]
+	for i, ix := range ixs {
+		if ix == from {
+			ixs[i] = to
+			return
+		}
+	}
+}
+
+type BinHeap[T any] struct {
+	elements []T
+	less     func(a, b T) bool
+	index    heapIndex[T]
+}
+
+// NewBinHeap creates a heap ordered by less: the element for which less
+// returns true comes out first. The heap does not track positions, so Find
+// always returns -1.
+func NewBinHeap[T any](less func(a, b T) bool) *BinHeap[T] {
+	return &BinHeap[T]{
+		elements: make([]T, 0, 1),
+		less:     less,
+	}
+}
+
+// NewBinHeapWithIndex creates a heap which additionally tracks the position of
+// every element, which makes Find work.
+func NewBinHeapWithIndex[T comparable](less func(a, b T) bool) *BinHeap[T] {
+	h := NewBinHeap(less)
+	h.index = make(mapIndex[T])
+	return h
+}
+
+func (h *BinHeap[T]) Size() int {
 	return len(h.elements)
 }
 
-func (h *BinHeap) Peek() interface{} {
+// Peek returns the top element, ok is false if the heap is empty.
+func (h *BinHeap[T]) Peek() (T, bool) {
 	if h.Size() == 0 {
-		return nil
+		var zero T
+		return zero, false
 	}
-	return h.elements[0]
+	return h.elements[0], true
 }
 
-func (h *BinHeap) Pop() interface{} {
-	if (h.Size()) == 0 {
-		return nil
+// Pop removes and returns the top element, ok is false if the heap is empty.
+func (h *BinHeap[T]) Pop() (T, bool) {
+	if h.Size() == 0 {
+		var zero T
+		return zero, false
 	}
 	last := len(h.elements) - 1
-	h.index[h.elements[0]] = last
-	h.index[h.elements[last]] = 0
-	h.elements[0], h.elements[last] = h.elements[last], h.elements[0]
+	h.swap(0, last)
 	res := h.elements[last]
-	delete(h.index, h.elements[last])
+	if h.index != nil {
+		h.index.del(res, last)
+	}
+	var zero T
+	h.elements[last] = zero
 	h.elements = h.elements[:last]
 	h.reheapDown(0)
-	return res
+	return res, true
 }
 
-func (h *BinHeap) Push(v interface{}) {
+func (h *BinHeap[T]) Push(v T) {
 	last := len(h.elements)
 	h.elements = append(h.elements, v)
-	h.index[h.elements[last]] = last
+	if h.index != nil {
+		h.index.add(v, last)
+	}
 	h.reheapUp(last)
 }
 
-func (h *BinHeap) ReheapAt(ix int) {
+// ReheapAt restores the heap order after the element at ix has changed its
+// priority.
+func (h *BinHeap[T]) ReheapAt(ix int) {
 	h.reheapUp(ix)
 	h.reheapDown(ix)
 }
 
-func (h *BinHeap) Find(v interface{}) int {
-	ix, ok := h.index[v]
+// Find returns the position of v in the heap or -1 if it is not there or the
+// heap is not indexed. If v was pushed several times, any of its positions
+// could be returned.
+func (h *BinHeap[T]) Find(v T) int {
+	if h.index == nil {
+		return -1
+	}
+	ix, ok := h.index.get(v)
 	if !ok {
 		return -1
 	}
 	return ix
 }
 
-func (h *BinHeap) reheapUp(ix int) {
+func (h *BinHeap[T]) reheapUp(ix int) {
 	ptr := ix
 	for ptr > 0 {
-		parent := (ix - 1) / 2
-		if !h.compare(ptr, parent) {
+		parent := (ptr - 1) / 2
+		if !h.less(h.elements[ptr], h.elements[parent]) {
 			break
 		}
-		h.index[h.elements[parent]] = ptr
-		h.index[h.elements[ptr]] = parent
-		h.elements[ptr], h.elements[parent] = h.elements[parent], h.elements[ptr]
+		h.swap(ptr, parent)
 		ptr = parent
 	}
 }
 
-func (h *BinHeap) reheapDown(ix int) {
+func (h *BinHeap[T]) reheapDown(ix int) {
 	ptr := ix
 	for ptr < len(h.elements) {
 		left, right := ptr*2+1, ptr*2+2
 		next := ptr
-		if left < len(h.elements) {
-			if h.compare(left, next) {
-				next = left
-			}
+		if left < len(h.elements) && h.less(h.elements[left], h.elements[next]) {
+			next = left
 		}
-		if right < len(h.elements) {
-			if h.compare(right, next) {
-				next = right
-			}
+		if right < len(h.elements) && h.less(h.elements[right], h.elements[next]) {
+			next = right
 		}
 		if next == ptr {
 			break
 		}
-		h.index[h.elements[next]] = ptr
-		h.index[h.elements[ptr]] = next
-		h.elements[ptr], h.elements[next] = h.elements[next], h.elements[ptr]
+		h.swap(ptr, next)
 		ptr = next
 	}
 }
 
-// compares elements at ix1 and ix2 and returns true if the one at ix1 is < than
-// the one at ix2
-func (h *BinHeap) compare(ix1, ix2 int) bool {
-	return h.comparator(h.elements[ix1], h.elements[ix2])
+func (h *BinHeap[T]) swap(ix1, ix2 int) {
+	if h.index != nil {
+		h.index.swap(h.elements[ix1], ix1, h.elements[ix2], ix2)
+	}
+	h.elements[ix1], h.elements[ix2] = h.elements[ix2], h.elements[ix1]
 }
@@ -1,58 +1,149 @@
-package main
+package lib
 
 import (
+	"math/rand"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-var (
-	MaxHeapInt Comparator = func(v1, v2 interface{}) bool {
-		i1, i2 := v1.(int), v2.(int)
-		return i1 > i2
-	}
+func minInt(a, b int) bool {
+	return a < b
+}
 
-	MinHeapInt Comparator = func(v1, v2 interface{}) bool {
-		i1, i2 := v1.(int), v2.(int)
-		return i1 < i2
-	}
-)
+func maxInt(a, b int) bool {
+	return a > b
+}
 
 func TestBinHeap_Size_Empty(t *testing.T) {
-	h := NewBinHeap(MaxHeapInt)
+	h := NewBinHeap(maxInt)
 	assert.Equal(t, 0, h.Size())
+	_, ok := h.Peek()
+	assert.False(t, ok)
+	_, ok = h.Pop()
+	assert.False(t, ok)
 }
 
 func TestBinHeap_MinHeap_Push(t *testing.T) {
-	h := NewBinHeap(MinHeapInt)
-	h.Push(1)
-	h.Push(2)
+	h := NewBinHeap(minInt)
 	h.Push(3)
-	assert.Equal(t, 1, h.Peek())
+	h.Push(2)
+	h.Push(1)
+	v, ok := h.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
 }
 
 func TestBinHeap_MaxHeap_Push(t *testing.T) {
-	h := NewBinHeap(MaxHeapInt)
+	h := NewBinHeap(maxInt)
 	h.Push(1)
 	h.Push(2)
 	h.Push(3)
-	assert.Equal(t, 3, h.Peek())
+	v, _ := h.Peek()
+	assert.Equal(t, 3, v)
 }
 
 func TestBinHeap_MinHeap_Pop(t *testing.T) {
-	h := NewBinHeap(MinHeapInt)
+	h := NewBinHeap(minInt)
 	h.Push(1)
 	h.Push(2)
 	h.Push(3)
-	h.Pop()
-	assert.Equal(t, 2, h.Peek())
+	v, _ := h.Pop()
+	assert.Equal(t, 1, v)
+	v, _ = h.Peek()
+	assert.Equal(t, 2, v)
 }
 
-func TestBinHeap_MaxHeap_Pop(t *testing.T) {
-	h := NewBinHeap(MaxHeapInt)
+func TestBinHeap_Sorts(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	vals := rnd.Perm(1000)
+	h := NewBinHeap(minInt)
+	for _, v := range vals {
+		h.Push(v)
+	}
+	sort.Ints(vals)
+	for _, exp := range vals {
+		v, ok := h.Pop()
+		assert.True(t, ok)
+		assert.Equal(t, exp, v)
+	}
+	assert.Equal(t, 0, h.Size())
+}
+
+func TestBinHeap_Find_NotIndexed(t *testing.T) {
+	h := NewBinHeap(minInt)
 	h.Push(1)
-	h.Push(2)
-	h.Push(3)
+	assert.Equal(t, -1, h.Find(1))
+}
+
+type heapItem struct {
+	name string
+	prio int
+}
+
+func TestBinHeap_Indexed_ReheapAt(t *testing.T) {
+	h := NewBinHeapWithIndex(func(a, b *heapItem) bool { return a.prio < b.prio })
+	items := []*heapItem{{"a", 5}, {"b", 4}, {"c", 3}, {"d", 2}, {"e", 1}}
+	for _, it := range items {
+		h.Push(it)
+	}
+	for _, it := range items {
+		ix := h.Find(it)
+		assert.True(t, ix >= 0)
+		assert.Same(t, it, h.elements[ix])
+	}
+	items[0].prio = 0
+	h.ReheapAt(h.Find(items[0]))
+	top, _ := h.Peek()
+	assert.Equal(t, "a", top.name)
+
 	h.Pop()
-	assert.Equal(t, 2, h.Peek())
+	assert.Equal(t, -1, h.Find(items[0]))
+}
+
+func benchValues(n int) []int {
+	return rand.New(rand.NewSource(1)).Perm(n)
+}
+
+func BenchmarkBinHeap_PushPop(b *testing.B) {
+	vals := benchValues(1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		h := NewBinHeap(minInt)
+		for _, v := range vals {
+			h.Push(v)
+		}
+		for h.Size() > 0 {
+			h.Pop()
+		}
+	}
+}
+
+func BenchmarkBinHeap_Indexed_PushPop(b *testing.B) {
+	vals := benchValues(1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		h := NewBinHeapWithIndex(minInt)
+		for _, v := range vals {
+			h.Push(v)
+		}
+		for h.Size() > 0 {
+			h.Pop()
+		}
+	}
+}
+
+func BenchmarkIfaceBinHeap_PushPop(b *testing.B) {
+	vals := benchValues(1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		h := NewIfaceBinHeap(MinHeapInt)
+		for _, v := range vals {
+			h.Push(v)
+		}
+		for h.Size() > 0 {
+			h.Pop()
+		}
+	}
 }
@@ -0,0 +1,203 @@
+// Package combinatorics enumerates permutations, combinations and cartesian
+// products of slices.
+package combinatorics
+
+// All the iterators below work on their own copy of the input and reuse a
+// single buffer for the values they produce: Value() (or the slice passed to
+// a callback) is only valid until the next step. Copy it if it has to be kept.
+
+// PermutationIter enumerates all permutations of a sequence using the
+// iterative form of Heap's algorithm.
+type PermutationIter[T any] struct {
+	buf     []T
+	counter []int
+	ptr     int
+	started bool
+}
+
+func NewPermutationIter[T any](seq []T) *PermutationIter[T] {
+	buf := make([]T, len(seq))
+	copy(buf, seq)
+	return &PermutationIter[T]{
+		buf:     buf,
+		counter: make([]int, len(seq)),
+	}
+}
+
+// Next advances the iterator and returns false once all the permutations
+// have been produced.
+func (it *PermutationIter[T]) Next() bool {
+	if !it.started {
+		it.started = true
+		return true
+	}
+	for it.ptr < len(it.buf) {
+		if it.counter[it.ptr] < it.ptr {
+			if it.ptr%2 == 0 {
+				it.buf[0], it.buf[it.ptr] = it.buf[it.ptr], it.buf[0]
+			} else {
+				c := it.counter[it.ptr]
+				it.buf[c], it.buf[it.ptr] = it.buf[it.ptr], it.buf[c]
+			}
+			it.counter[it.ptr]++
+			it.ptr = 0
+			return true
+		}
+		it.counter[it.ptr] = 0
+		it.ptr++
+	}
+	return false
+}
+
+func (it *PermutationIter[T]) Value() []T {
+	return it.buf
+}
+
+// Permutations calls fn with every permutation of seq until fn returns false.
+func Permutations[T any](seq []T, fn func([]T) bool) {
+	it := NewPermutationIter(seq)
+	for it.Next() {
+		if !fn(it.Value()) {
+			return
+		}
+	}
+}
+
+// CombinationIter enumerates all k-element subsets of a sequence in
+// lexicographic order of the element positions.
+type CombinationIter[T any] struct {
+	seq     []T
+	buf     []T
+	ixs     []int
+	started bool
+	done    bool
+}
+
+func NewCombinationIter[T any](seq []T, k int) *CombinationIter[T] {
+	it := &CombinationIter[T]{
+		seq:  make([]T, len(seq)),
+		done: k < 0 || k > len(seq),
+	}
+	copy(it.seq, seq)
+	if it.done {
+		return it
+	}
+	it.buf = make([]T, k)
+	it.ixs = make([]int, k)
+	for ix := 0; ix < k; ix++ {
+		it.ixs[ix] = ix
+		it.buf[ix] = it.seq[ix]
+	}
+	return it
+}
+
+// Next advances the iterator and returns false once all the combinations
+// have been produced.
+func (it *CombinationIter[T]) Next() bool {
+	if it.done {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		return true
+	}
+	n, k := len(it.seq), len(it.ixs)
+	ptr := k - 1
+	for ptr >= 0 && it.ixs[ptr] == n-k+ptr {
+		ptr--
+	}
+	if ptr < 0 {
+		it.done = true
+		return false
+	}
+	it.ixs[ptr]++
+	it.buf[ptr] = it.seq[it.ixs[ptr]]
+	for ix := ptr + 1; ix < k; ix++ {
+		it.ixs[ix] = it.ixs[ix-1] + 1
+		it.buf[ix] = it.seq[it.ixs[ix]]
+	}
+	return true
+}
+
+func (it *CombinationIter[T]) Value() []T {
+	return it.buf
+}
+
+// Combinations calls fn with every k-element subset of seq until fn returns
+// false.
+func Combinations[T any](seq []T, k int, fn func([]T) bool) {
+	it := NewCombinationIter(seq, k)
+	for it.Next() {
+		if !fn(it.Value()) {
+			return
+		}
+	}
+}
+
+// ProductIter enumerates the cartesian product of several sequences, the last
+// sequence changing the fastest.
+type ProductIter[T any] struct {
+	seqs    [][]T
+	buf     []T
+	ixs     []int
+	started bool
+	done    bool
+}
+
+func NewProductIter[T any](seqs [][]T) *ProductIter[T] {
+	it := &ProductIter[T]{
+		seqs: make([][]T, len(seqs)),
+		buf:  make([]T, len(seqs)),
+		ixs:  make([]int, len(seqs)),
+	}
+	for ix, seq := range seqs {
+		if len(seq) == 0 {
+			it.done = true
+			return it
+		}
+		it.seqs[ix] = make([]T, len(seq))
+		copy(it.seqs[ix], seq)
+		it.buf[ix] = seq[0]
+	}
+	return it
+}
+
+// Next advances the iterator and returns false once all the tuples have been
+// produced.
+func (it *ProductIter[T]) Next() bool {
+	if it.done {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		return true
+	}
+	ptr := len(it.ixs) - 1
+	for ptr >= 0 {
+		it.ixs[ptr]++
+		if it.ixs[ptr] < len(it.seqs[ptr]) {
+			it.buf[ptr] = it.seqs[ptr][it.ixs[ptr]]
+			return true
+		}
+		it.ixs[ptr] = 0
+		it.buf[ptr] = it.seqs[ptr][0]
+		ptr--
+	}
+	it.done = true
+	return false
+}
+
+func (it *ProductIter[T]) Value() []T {
+	return it.buf
+}
+
+// Product calls fn with every tuple of the cartesian product of seqs until fn
+// returns false.
+func Product[T any](seqs [][]T, fn func([]T) bool) {
+	it := NewProductIter(seqs)
+	for it.Next() {
+		if !fn(it.Value()) {
+			return
+		}
+	}
+}
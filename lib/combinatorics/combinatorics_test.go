@@ -0,0 +1,196 @@
+package combinatorics
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func collect[T any](iter func(func([]T) bool)) [][]T {
+	res := make([][]T, 0, 1)
+	iter(func(v []T) bool {
+		cp := make([]T, len(v))
+		copy(cp, v)
+		res = append(res, cp)
+		return true
+	})
+	return res
+}
+
+func asStrings[T any](vs [][]T) []string {
+	res := make([]string, 0, len(vs))
+	for _, v := range vs {
+		res = append(res, fmt.Sprintf("%v", v))
+	}
+	sort.Strings(res)
+	return res
+}
+
+func TestPermutations_Empty(t *testing.T) {
+	perms := collect(func(fn func([]int) bool) { Permutations([]int{}, fn) })
+	assert.Equal(t, [][]int{{}}, perms)
+}
+
+func TestPermutations_All(t *testing.T) {
+	perms := collect(func(fn func([]int) bool) { Permutations([]int{1, 2, 3}, fn) })
+	assert.Equal(t, []string{
+		"[1 2 3]", "[1 3 2]", "[2 1 3]", "[2 3 1]", "[3 1 2]", "[3 2 1]",
+	}, asStrings(perms))
+}
+
+func TestPermutations_Unique(t *testing.T) {
+	seq := []int{5, 6, 7, 8, 9}
+	perms := collect(func(fn func([]int) bool) { Permutations(seq, fn) })
+	assert.Len(t, perms, 120)
+	seen := make(map[string]bool)
+	for _, p := range asStrings(perms) {
+		assert.False(t, seen[p], "duplicate permutation %s", p)
+		seen[p] = true
+	}
+	assert.Equal(t, []int{5, 6, 7, 8, 9}, seq, "input must stay untouched")
+}
+
+func TestPermutations_Stop(t *testing.T) {
+	cnt := 0
+	Permutations([]string{"a", "b", "c", "d"}, func([]string) bool {
+		cnt++
+		return cnt < 3
+	})
+	assert.Equal(t, 3, cnt)
+}
+
+func TestPermutationIter_Next(t *testing.T) {
+	it := NewPermutationIter([]byte("ab"))
+	assert.True(t, it.Next())
+	assert.Equal(t, "ab", string(it.Value()))
+	assert.True(t, it.Next())
+	assert.Equal(t, "ba", string(it.Value()))
+	assert.False(t, it.Next())
+	assert.False(t, it.Next())
+}
+
+func TestCombinations_All(t *testing.T) {
+	combs := collect(func(fn func([]int) bool) { Combinations([]int{1, 2, 3, 4}, 2, fn) })
+	assert.Equal(t, [][]int{{1, 2}, {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4}}, combs)
+}
+
+func TestCombinations_Edges(t *testing.T) {
+	seq := []int{1, 2, 3}
+	assert.Equal(t, [][]int{{}}, collect(func(fn func([]int) bool) { Combinations(seq, 0, fn) }))
+	assert.Equal(t, [][]int{{1, 2, 3}}, collect(func(fn func([]int) bool) { Combinations(seq, 3, fn) }))
+	assert.Empty(t, collect(func(fn func([]int) bool) { Combinations(seq, 4, fn) }))
+	assert.Empty(t, collect(func(fn func([]int) bool) { Combinations(seq, -1, fn) }))
+}
+
+func TestCombinations_Count(t *testing.T) {
+	seq := make([]int, 10)
+	cnt := 0
+	Combinations(seq, 4, func([]int) bool {
+		cnt++
+		return true
+	})
+	assert.Equal(t, 210, cnt)
+}
+
+func TestCombinations_Stop(t *testing.T) {
+	cnt := 0
+	Combinations([]int{1, 2, 3, 4, 5}, 3, func([]int) bool {
+		cnt++
+		return false
+	})
+	assert.Equal(t, 1, cnt)
+}
+
+func TestProduct_All(t *testing.T) {
+	prod := collect(func(fn func([]string) bool) {
+		Product([][]string{{"a", "b"}, {"x"}, {"1", "2"}}, fn)
+	})
+	assert.Equal(t, [][]string{
+		{"a", "x", "1"}, {"a", "x", "2"}, {"b", "x", "1"}, {"b", "x", "2"},
+	}, prod)
+}
+
+func TestProduct_Edges(t *testing.T) {
+	assert.Equal(t, [][]int{{}}, collect(func(fn func([]int) bool) { Product([][]int{}, fn) }))
+	assert.Empty(t, collect(func(fn func([]int) bool) { Product([][]int{{1, 2}, {}}, fn) }))
+}
+
+func TestProduct_Stop(t *testing.T) {
+	cnt := 0
+	Product([][]int{{1, 2, 3}, {1, 2, 3}}, func(v []int) bool {
+		cnt++
+		return v[1] != 2
+	})
+	assert.Equal(t, 2, cnt)
+}
+
+func BenchmarkPermutations(b *testing.B) {
+	seq := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Permutations(seq, func([]int) bool { return true })
+	}
+}
+
+func BenchmarkPermutationIter(b *testing.B) {
+	seq := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		it := NewPermutationIter(seq)
+		for it.Next() {
+		}
+	}
+}
+
+// the goroutine-driven generator from day-7, kept as a baseline
+func BenchmarkPermutations_Chan(b *testing.B) {
+	permutate := func(seq []int) <-chan []int {
+		ch := make(chan []int)
+		var perm func(int)
+		perm = func(k int) {
+			if k == 1 {
+				cp := make([]int, len(seq))
+				copy(cp, seq)
+				ch <- cp
+			} else {
+				for i := 0; i < k; i++ {
+					perm(k - 1)
+					if k%2 > 0 {
+						seq[0], seq[k-1] = seq[k-1], seq[0]
+					} else {
+						seq[i], seq[k-1] = seq[k-1], seq[i]
+					}
+				}
+			}
+			if k == len(seq) {
+				close(ch)
+			}
+		}
+		go perm(len(seq))
+		return ch
+	}
+	seq := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for range permutate(seq) {
+		}
+	}
+}
+
+func BenchmarkCombinations(b *testing.B) {
+	seq := make([]int, 20)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Combinations(seq, 5, func([]int) bool { return true })
+	}
+}
+
+func BenchmarkProduct(b *testing.B) {
+	seqs := [][]int{{0, 1, 2, 3}, {0, 1, 2, 3}, {0, 1, 2, 3}, {0, 1, 2, 3}, {0, 1, 2, 3}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Product(seqs, func([]int) bool { return true })
+	}
+}
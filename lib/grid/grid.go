@@ -0,0 +1,226 @@
+// Package grid implements a rectangular character map, the input format of
+// most of the map puzzles.
+package grid
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+type Point struct {
+	X, Y int
+}
+
+func (p Point) Add(o Point) Point {
+	return Point{p.X + o.X, p.Y + o.Y}
+}
+
+func (p Point) Sub(o Point) Point {
+	return Point{p.X - o.X, p.Y - o.Y}
+}
+
+var (
+	Up    = Point{0, -1}
+	Down  = Point{0, 1}
+	Left  = Point{-1, 0}
+	Right = Point{1, 0}
+
+	// Dirs4 are the orthogonal steps
+	Dirs4 = []Point{Right, Left, Down, Up}
+	// Dirs8 are the orthogonal and the diagonal steps
+	Dirs8 = []Point{Right, Left, Down, Up, {1, 1}, {-1, 1}, {1, -1}, {-1, -1}}
+)
+
+type Transformation int
+
+const (
+	ROTATE_90 Transformation = iota
+	ROTATE_180
+	ROTATE_270
+	FLIP_H
+	FLIP_V
+)
+
+// Grid is a rectangular map of bytes. Y grows downwards, the top left corner
+// is at {0, 0}.
+type Grid struct {
+	rows [][]byte
+	w, h int
+}
+
+// New creates a w x h grid filled with ch.
+func New(w, h int, ch byte) *Grid {
+	rows := make([][]byte, h)
+	for y := range rows {
+		rows[y] = bytes.Repeat([]byte{ch}, w)
+	}
+	return &Grid{
+		rows: rows,
+		w:    w,
+		h:    h,
+	}
+}
+
+// FromRows creates a grid on top of the given rows, they are not copied. All
+// the rows must be of the same width.
+func FromRows(rows [][]byte) (*Grid, error) {
+	g := &Grid{
+		rows: rows,
+		h:    len(rows),
+	}
+	for y, row := range rows {
+		if y == 0 {
+			g.w = len(row)
+			continue
+		}
+		if len(row) != g.w {
+			return nil, fmt.Errorf("row %d is %d wide, want %d", y, len(row), g.w)
+		}
+	}
+	return g, nil
+}
+
+// Parse splits data into rows. The surrounding blank lines are dropped and
+// Windows line endings are accepted.
+func Parse(data []byte) (*Grid, error) {
+	data = bytes.Trim(data, "\n\r")
+	if len(data) == 0 {
+		return &Grid{}, nil
+	}
+	lines := bytes.Split(data, []byte{'\n'})
+	rows := make([][]byte, 0, len(lines))
+	for _, line := range lines {
+		row := make([]byte, len(line))
+		copy(row, line)
+		rows = append(rows, bytes.TrimRight(row, "\r"))
+	}
+	return FromRows(rows)
+}
+
+func Read(in io.Reader) (*Grid, error) {
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+func (g *Grid) Width() int {
+	return g.w
+}
+
+func (g *Grid) Height() int {
+	return g.h
+}
+
+func (g *Grid) In(p Point) bool {
+	return p.X >= 0 && p.Y >= 0 && p.X < g.w && p.Y < g.h
+}
+
+// At returns the byte at p, ok is false if p is out of the grid.
+func (g *Grid) At(p Point) (byte, bool) {
+	if !g.In(p) {
+		return 0, false
+	}
+	return g.rows[p.Y][p.X], true
+}
+
+// Set puts ch at p and returns false if p is out of the grid.
+func (g *Grid) Set(p Point, ch byte) bool {
+	if !g.In(p) {
+		return false
+	}
+	g.rows[p.Y][p.X] = ch
+	return true
+}
+
+// Neighbors4 returns the orthogonal neighbors of p which are within the grid.
+func (g *Grid) Neighbors4(p Point) []Point {
+	return g.neighbors(p, Dirs4)
+}
+
+// Neighbors8 returns the orthogonal and the diagonal neighbors of p which are
+// within the grid.
+func (g *Grid) Neighbors8(p Point) []Point {
+	return g.neighbors(p, Dirs8)
+}
+
+func (g *Grid) neighbors(p Point, dirs []Point) []Point {
+	res := make([]Point, 0, len(dirs))
+	for _, d := range dirs {
+		if np := p.Add(d); g.In(np) {
+			res = append(res, np)
+		}
+	}
+	return res
+}
+
+// Find returns all the positions of ch row by row.
+func (g *Grid) Find(ch byte) []Point {
+	res := make([]Point, 0, 1)
+	for y, row := range g.rows {
+		for x, c := range row {
+			if c == ch {
+				res = append(res, Point{x, y})
+			}
+		}
+	}
+	return res
+}
+
+// Rows returns the underlying rows, changing them changes the grid.
+func (g *Grid) Rows() [][]byte {
+	return g.rows
+}
+
+func (g *Grid) Clone() *Grid {
+	rows := make([][]byte, len(g.rows))
+	for y, row := range g.rows {
+		rows[y] = make([]byte, len(row))
+		copy(rows[y], row)
+	}
+	return &Grid{
+		rows: rows,
+		w:    g.w,
+		h:    g.h,
+	}
+}
+
+// Transform returns a new grid rotated clockwise or flipped horizontally
+// (left to right) or vertically (top to bottom).
+func (g *Grid) Transform(t Transformation) *Grid {
+	var res *Grid
+	switch t {
+	case ROTATE_90, ROTATE_270:
+		res = New(g.h, g.w, 0)
+	case ROTATE_180, FLIP_H, FLIP_V:
+		res = New(g.w, g.h, 0)
+	default:
+		panic(fmt.Sprintf("Unknown transformation: %d", t))
+	}
+	for y, row := range g.rows {
+		for x, ch := range row {
+			var p Point
+			switch t {
+			case ROTATE_90:
+				p = Point{g.h - 1 - y, x}
+			case ROTATE_180:
+				p = Point{g.w - 1 - x, g.h - 1 - y}
+			case ROTATE_270:
+				p = Point{y, g.w - 1 - x}
+			case FLIP_H:
+				p = Point{g.w - 1 - x, y}
+			case FLIP_V:
+				p = Point{x, g.h - 1 - y}
+			}
+			res.rows[p.Y][p.X] = ch
+		}
+	}
+	return res
+}
+
+func (g *Grid) String() string {
+	return string(bytes.Join(g.rows, []byte{'\n'}))
+}
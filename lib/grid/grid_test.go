@@ -0,0 +1,92 @@
+package grid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustParse(t *testing.T, s string) *Grid {
+	g, err := Parse([]byte(s))
+	assert.NoError(t, err)
+	return g
+}
+
+func TestParse(t *testing.T) {
+	g := mustParse(t, "\n#.#\r\n.@.\r\n#.#\n\n")
+	assert.Equal(t, 3, g.Width())
+	assert.Equal(t, 3, g.Height())
+	assert.Equal(t, "#.#\n.@.\n#.#", g.String())
+}
+
+func TestParse_Ragged(t *testing.T) {
+	_, err := Parse([]byte("###\n##\n###"))
+	assert.Error(t, err)
+}
+
+func TestRead(t *testing.T) {
+	g, err := Read(strings.NewReader("ab\ncd\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("ab"), []byte("cd")}, g.Rows())
+}
+
+func TestGrid_At(t *testing.T) {
+	g := mustParse(t, "ab\ncd")
+	ch, ok := g.At(Point{1, 0})
+	assert.True(t, ok)
+	assert.Equal(t, byte('b'), ch)
+	for _, p := range []Point{{-1, 0}, {0, -1}, {2, 0}, {0, 2}} {
+		_, ok := g.At(p)
+		assert.False(t, ok, "%+v must be out of the grid", p)
+	}
+}
+
+func TestGrid_Set(t *testing.T) {
+	g := New(2, 2, '.')
+	assert.True(t, g.Set(Point{1, 1}, '#'))
+	assert.False(t, g.Set(Point{2, 1}, '#'))
+	assert.Equal(t, "..\n.#", g.String())
+}
+
+func TestGrid_Neighbors(t *testing.T) {
+	g := New(3, 3, '.')
+	assert.ElementsMatch(t, []Point{{1, 0}, {0, 1}}, g.Neighbors4(Point{0, 0}))
+	assert.ElementsMatch(t, []Point{{1, 0}, {0, 1}, {1, 1}}, g.Neighbors8(Point{0, 0}))
+	assert.Len(t, g.Neighbors4(Point{1, 1}), 4)
+	assert.Len(t, g.Neighbors8(Point{1, 1}), 8)
+}
+
+func TestGrid_Find(t *testing.T) {
+	g := mustParse(t, "#.#\n.#.")
+	assert.Equal(t, []Point{{0, 0}, {2, 0}, {1, 1}}, g.Find('#'))
+	assert.Empty(t, g.Find('@'))
+}
+
+func TestGrid_Transform(t *testing.T) {
+	g := mustParse(t, "abc\ndef")
+	tests := []struct {
+		t    Transformation
+		want string
+	}{
+		{ROTATE_90, "da\neb\nfc"},
+		{ROTATE_180, "fed\ncba"},
+		{ROTATE_270, "cf\nbe\nad"},
+		{FLIP_H, "cba\nfed"},
+		{FLIP_V, "def\nabc"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, g.Transform(tt.t).String())
+	}
+	assert.Equal(t, "abc\ndef", g.String(), "the original grid must stay untouched")
+	full := g.Transform(ROTATE_90).Transform(ROTATE_90).Transform(ROTATE_90).Transform(ROTATE_90)
+	assert.Equal(t, g.String(), full.String())
+}
+
+func TestGrid_Clone(t *testing.T) {
+	g := mustParse(t, "ab")
+	cp := g.Clone()
+	cp.Set(Point{0, 0}, 'x')
+	assert.Equal(t, "ab", g.String())
+	assert.Equal(t, "xb", cp.String())
+}
@@ -0,0 +1,45 @@
+package lib
+
+type Comparator func(v1, v2 interface{}) bool
+
+// IfaceBinHeap is the original heap API storing boxed values, now a thin
+// wrapper around an indexed BinHeap. New code should use BinHeap[T], this one
+// is kept around for the existing users and as a benchmark baseline.
+type IfaceBinHeap struct {
+	heap *BinHeap[interface{}]
+}
+
+func NewIfaceBinHeap(cmp Comparator) *IfaceBinHeap {
+	h := NewBinHeap[interface{}](cmp)
+	h.index = make(mapIndex[interface{}])
+	return &IfaceBinHeap{heap: h}
+}
+
+func (h *IfaceBinHeap) Size() int {
+	return h.heap.Size()
+}
+
+// Peek returns the top element or nil if the heap is empty.
+func (h *IfaceBinHeap) Peek() interface{} {
+	v, _ := h.heap.Peek()
+	return v
+}
+
+// Pop removes and returns the top element or nil if the heap is empty.
+func (h *IfaceBinHeap) Pop() interface{} {
+	v, _ := h.heap.Pop()
+	return v
+}
+
+func (h *IfaceBinHeap) Push(v interface{}) {
+	h.heap.Push(v)
+}
+
+func (h *IfaceBinHeap) ReheapAt(ix int) {
+	h.heap.ReheapAt(ix)
+}
+
+// Find works like BinHeap.Find.
+func (h *IfaceBinHeap) Find(v interface{}) int {
+	return h.heap.Find(v)
+}
@@ -1,6 +1,204 @@
-package main
+package lib
 
-type IndexedBinHeap struct {
-	*BinHeap
-	index map[interface{}]int
+// Handle identifies an element pushed to an IndexedBinHeap. The slots behind
+// the handles are reused once their elements leave the heap, so the memory
+// follows the heap size rather than the number of pushes. A handle carries
+// the generation of its slot, so a stale one can not accidentally point to a
+// newer element.
+type Handle int64
+
+const HANDLE_SLOT_BITS = 32
+
+func newHandle(slot, gen int) Handle {
+	return Handle(int64(gen)<<HANDLE_SLOT_BITS | int64(slot))
+}
+
+func (h Handle) slot() int {
+	return int(h & (1<<HANDLE_SLOT_BITS - 1))
+}
+
+func (h Handle) gen() int {
+	return int(h >> HANDLE_SLOT_BITS)
+}
+
+// handleSlot is the position of the element a handle points to, -1 while the
+// slot is free.
+type handleSlot struct {
+	pos int
+	gen int
+}
+
+type indexedEntry[V any, P any] struct {
+	handle Handle
+	value  V
+	prio   P
+}
+
+// IndexedBinHeap is a priority queue which allows changing the priority of
+// and removing the elements it holds, addressing them by the handles Push
+// returns. Values do not have to be comparable nor unique.
+type IndexedBinHeap[V any, P any] struct {
+	entries []indexedEntry[V, P]
+	slots   []handleSlot
+	// the slots of the elements which left the heap
+	free []int
+	less func(a, b P) bool
+}
+
+// NewIndexedBinHeap creates a heap where the element with the priority for
+// which less returns true comes out first.
+func NewIndexedBinHeap[V any, P any](less func(a, b P) bool) *IndexedBinHeap[V, P] {
+	return &IndexedBinHeap[V, P]{
+		entries: make([]indexedEntry[V, P], 0, 1),
+		slots:   make([]handleSlot, 0, 1),
+		less:    less,
+	}
+}
+
+func (h *IndexedBinHeap[V, P]) Size() int {
+	return len(h.entries)
+}
+
+func (h *IndexedBinHeap[V, P]) Push(value V, prio P) Handle {
+	last := len(h.entries)
+	var slot int
+	if n := len(h.free); n > 0 {
+		slot = h.free[n-1]
+		h.free = h.free[:n-1]
+		h.slots[slot].pos = last
+	} else {
+		slot = len(h.slots)
+		h.slots = append(h.slots, handleSlot{pos: last})
+	}
+	handle := newHandle(slot, h.slots[slot].gen)
+	h.entries = append(h.entries, indexedEntry[V, P]{
+		handle: handle,
+		value:  value,
+		prio:   prio,
+	})
+	h.reheapUp(last)
+	return handle
+}
+
+// Peek returns the top element without removing it, ok is false if the heap
+// is empty.
+func (h *IndexedBinHeap[V, P]) Peek() (value V, prio P, ok bool) {
+	if h.Size() == 0 {
+		return
+	}
+	return h.entries[0].value, h.entries[0].prio, true
+}
+
+// Pop removes and returns the top element, ok is false if the heap is empty.
+func (h *IndexedBinHeap[V, P]) Pop() (value V, prio P, ok bool) {
+	if h.Size() == 0 {
+		return
+	}
+	return h.removeAt(0)
+}
+
+// Contains tells whether the element behind handle is still in the heap.
+func (h *IndexedBinHeap[V, P]) Contains(handle Handle) bool {
+	return h.position(handle) >= 0
+}
+
+// Get returns the element behind handle, ok is false if it is not in the heap
+// anymore.
+func (h *IndexedBinHeap[V, P]) Get(handle Handle) (value V, prio P, ok bool) {
+	ix := h.position(handle)
+	if ix < 0 {
+		return
+	}
+	return h.entries[ix].value, h.entries[ix].prio, true
+}
+
+// Update sets a new priority for the element behind handle and moves it
+// accordingly. Returns false if the element is not in the heap anymore.
+func (h *IndexedBinHeap[V, P]) Update(handle Handle, prio P) bool {
+	ix := h.position(handle)
+	if ix < 0 {
+		return false
+	}
+	h.entries[ix].prio = prio
+	h.reheapUp(ix)
+	h.reheapDown(h.slots[handle.slot()].pos)
+	return true
+}
+
+// Remove takes the element behind handle out of the heap, ok is false if it
+// is not there anymore.
+func (h *IndexedBinHeap[V, P]) Remove(handle Handle) (value V, prio P, ok bool) {
+	ix := h.position(handle)
+	if ix < 0 {
+		return
+	}
+	return h.removeAt(ix)
+}
+
+func (h *IndexedBinHeap[V, P]) position(handle Handle) int {
+	slot := handle.slot()
+	if handle < 0 || slot >= len(h.slots) || h.slots[slot].gen != handle.gen() {
+		return -1
+	}
+	return h.slots[slot].pos
+}
+
+func (h *IndexedBinHeap[V, P]) removeAt(ix int) (V, P, bool) {
+	last := len(h.entries) - 1
+	h.swap(ix, last)
+	res := h.entries[last]
+	h.release(res.handle)
+	h.entries[last] = indexedEntry[V, P]{}
+	h.entries = h.entries[:last]
+	if ix < last {
+		h.reheapUp(ix)
+		h.reheapDown(h.slots[h.entries[ix].handle.slot()].pos)
+	}
+	return res.value, res.prio, true
+}
+
+// release frees the slot of the handle and moves the slot to the next
+// generation, invalidating the handle.
+func (h *IndexedBinHeap[V, P]) release(handle Handle) {
+	slot := handle.slot()
+	h.slots[slot].pos = -1
+	h.slots[slot].gen++
+	h.free = append(h.free, slot)
+}
+
+func (h *IndexedBinHeap[V, P]) reheapUp(ix int) {
+	ptr := ix
+	for ptr > 0 {
+		parent := (ptr - 1) / 2
+		if !h.less(h.entries[ptr].prio, h.entries[parent].prio) {
+			break
+		}
+		h.swap(ptr, parent)
+		ptr = parent
+	}
+}
+
+func (h *IndexedBinHeap[V, P]) reheapDown(ix int) {
+	ptr := ix
+	for ptr < len(h.entries) {
+		left, right := ptr*2+1, ptr*2+2
+		next := ptr
+		if left < len(h.entries) && h.less(h.entries[left].prio, h.entries[next].prio) {
+			next = left
+		}
+		if right < len(h.entries) && h.less(h.entries[right].prio, h.entries[next].prio) {
+			next = right
+		}
+		if next == ptr {
+			break
+		}
+		h.swap(ptr, next)
+		ptr = next
+	}
+}
+
+func (h *IndexedBinHeap[V, P]) swap(ix1, ix2 int) {
+	h.entries[ix1], h.entries[ix2] = h.entries[ix2], h.entries[ix1]
+	h.slots[h.entries[ix1].handle.slot()].pos = ix1
+	h.slots[h.entries[ix2].handle.slot()].pos = ix2
 }
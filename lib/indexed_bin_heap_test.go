@@ -0,0 +1,215 @@
+package lib
+
+import (
+	"sort"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexedBinHeap_Empty(t *testing.T) {
+	h := NewIndexedBinHeap[string](minInt)
+	assert.Equal(t, 0, h.Size())
+	_, _, ok := h.Pop()
+	assert.False(t, ok)
+	_, _, ok = h.Peek()
+	assert.False(t, ok)
+	assert.False(t, h.Update(0, 1))
+	_, _, ok = h.Remove(0)
+	assert.False(t, ok)
+}
+
+func TestIndexedBinHeap_Update(t *testing.T) {
+	h := NewIndexedBinHeap[string](minInt)
+	a := h.Push("a", 10)
+	b := h.Push("b", 20)
+	c := h.Push("c", 30)
+
+	assert.True(t, h.Update(c, 5))
+	v, p, _ := h.Peek()
+	assert.Equal(t, "c", v)
+	assert.Equal(t, 5, p)
+
+	assert.True(t, h.Update(c, 50))
+	v, _, _ = h.Peek()
+	assert.Equal(t, "a", v)
+
+	h.Pop()
+	assert.False(t, h.Contains(a))
+	assert.False(t, h.Update(a, 1))
+	assert.True(t, h.Contains(b))
+	_, p, _ = h.Get(b)
+	assert.Equal(t, 20, p)
+}
+
+func TestIndexedBinHeap_Remove(t *testing.T) {
+	h := NewIndexedBinHeap[string](minInt)
+	h.Push("a", 1)
+	b := h.Push("b", 2)
+	h.Push("c", 3)
+	v, p, ok := h.Remove(b)
+	assert.True(t, ok)
+	assert.Equal(t, "b", v)
+	assert.Equal(t, 2, p)
+	_, _, ok = h.Remove(b)
+	assert.False(t, ok)
+	assert.Equal(t, 2, h.Size())
+}
+
+func TestIndexedBinHeap_DuplicateValues(t *testing.T) {
+	h := NewIndexedBinHeap[string](minInt)
+	x1 := h.Push("x", 3)
+	x2 := h.Push("x", 2)
+	assert.True(t, h.Update(x1, 1))
+	_, p, _ := h.Pop()
+	assert.Equal(t, 1, p)
+	_, p, _ = h.Pop()
+	assert.Equal(t, 2, p)
+	assert.False(t, h.Contains(x2))
+}
+
+func TestIndexedBinHeap_ReusedHandles(t *testing.T) {
+	h := NewIndexedBinHeap[string](minInt)
+	a := h.Push("a", 1)
+	h.Pop()
+	b := h.Push("b", 2)
+	assert.NotEqual(t, a, b)
+	assert.False(t, h.Contains(a))
+	assert.False(t, h.Update(a, 0))
+	_, _, ok := h.Get(a)
+	assert.False(t, ok)
+	v, _, ok := h.Get(b)
+	assert.True(t, ok)
+	assert.Equal(t, "b", v)
+
+	// the slots follow the heap size, not the number of pushes
+	for i := 0; i < 1000; i++ {
+		h.Push("x", i)
+		h.Push("y", i)
+		h.Pop()
+		h.Pop()
+	}
+	assert.Equal(t, 1, h.Size())
+	assert.LessOrEqual(t, len(h.slots), 3)
+}
+
+// Pushes prios, applies the updates and removals to pseudo-randomly picked
+// handles and checks the heap drains in the same order as a sorted copy of
+// whatever should have been left.
+func TestIndexedBinHeap_Property(t *testing.T) {
+	prop := func(prios []int16, updates []int16, removes []uint8) bool {
+		if len(prios) == 0 {
+			return true
+		}
+		h := NewIndexedBinHeap[int](func(a, b int16) bool { return a < b })
+		model := make(map[Handle]int16)
+		handles := make([]Handle, 0, len(prios))
+		for ix, p := range prios {
+			handle := h.Push(ix, p)
+			handles = append(handles, handle)
+			model[handle] = p
+		}
+		for ix, p := range updates {
+			handle := handles[(ix*7+int(uint16(p)))%len(handles)]
+			_, live := model[handle]
+			if h.Update(handle, p) != live {
+				return false
+			}
+			if live {
+				model[handle] = p
+			}
+		}
+		for _, r := range removes {
+			handle := handles[int(r)%len(handles)]
+			_, live := model[handle]
+			_, p, ok := h.Remove(handle)
+			if ok != live || (live && p != model[handle]) {
+				return false
+			}
+			delete(model, handle)
+		}
+		if h.Size() != len(model) {
+			return false
+		}
+		exp := make([]int, 0, len(model))
+		for _, p := range model {
+			exp = append(exp, int(p))
+		}
+		sort.Ints(exp)
+		for _, p := range exp {
+			v, got, ok := h.Pop()
+			if !ok || int(got) != p || model[handles[v]] != got {
+				return false
+			}
+		}
+		return h.Size() == 0
+	}
+	assert.NoError(t, quick.Check(prop, &quick.Config{MaxCount: 500}))
+}
+
+func TestBinHeap_Property(t *testing.T) {
+	prop := func(vals []int) bool {
+		h := NewBinHeapWithIndex(minInt)
+		for _, v := range vals {
+			h.Push(v)
+		}
+		for _, v := range vals {
+			ix := h.Find(v)
+			if ix < 0 || h.elements[ix] != v {
+				return false
+			}
+		}
+		exp := make([]int, len(vals))
+		copy(exp, vals)
+		sort.Ints(exp)
+		for _, v := range exp {
+			got, ok := h.Pop()
+			if !ok || got != v {
+				return false
+			}
+		}
+		return h.Size() == 0 && len(h.index.(mapIndex[int])) == 0
+	}
+	assert.NoError(t, quick.Check(prop, &quick.Config{MaxCount: 500}))
+}
+
+func TestIfaceBinHeap_Property(t *testing.T) {
+	prop := func(vals []uint8) bool {
+		h := NewIfaceBinHeap(MinHeapInt)
+		for _, v := range vals {
+			h.Push(int(v))
+		}
+		exp := make([]int, 0, len(vals))
+		for _, v := range vals {
+			exp = append(exp, int(v))
+		}
+		sort.Ints(exp)
+		for _, v := range exp {
+			if h.Pop() != v {
+				return false
+			}
+		}
+		return h.Size() == 0 && len(h.heap.index.(mapIndex[interface{}])) == 0
+	}
+	assert.NoError(t, quick.Check(prop, &quick.Config{MaxCount: 500}))
+}
+
+func TestIfaceBinHeap_ReheapUp_Deep(t *testing.T) {
+	h := NewIfaceBinHeap(MinHeapInt)
+	for _, v := range []int{10, 20, 30, 40, 50, 60, 70, 5} {
+		h.Push(v)
+	}
+	assert.Equal(t, 5, h.Peek())
+	assert.Equal(t, 0, h.Find(5))
+}
+
+func TestIfaceBinHeap_Find_Duplicates(t *testing.T) {
+	h := NewIfaceBinHeap(MinHeapInt)
+	h.Push(1)
+	h.Push(1)
+	h.Pop()
+	assert.Equal(t, 0, h.Find(1))
+	h.Pop()
+	assert.Equal(t, -1, h.Find(1))
+}